@@ -0,0 +1,80 @@
+package channelz
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// addrToString formats a channelz address: host:port for TCP, with IPv6 hosts in brackets,
+// unix:PATH for Unix domain sockets, and the name with the type of its value for other
+// addresses, such as the ones of in-process transports. Empty when addr is nil.
+func addrToString(addr *channelzpb.Address) string {
+	switch a := addr.GetAddress().(type) {
+	case *channelzpb.Address_TcpipAddress:
+		port := fmt.Sprint(a.TcpipAddress.GetPort())
+		ip := a.TcpipAddress.GetIpAddress()
+		if len(ip) == 0 {
+			return net.JoinHostPort("", port)
+		}
+		return net.JoinHostPort(net.IP(ip).String(), port)
+	case *channelzpb.Address_UdsAddress_:
+		return "unix:" + a.UdsAddress.GetFilename()
+	case *channelzpb.Address_OtherAddress_:
+		name := a.OtherAddress.GetName()
+		if v := a.OtherAddress.GetValue(); v != nil {
+			typ := v.GetTypeUrl()
+			typ = typ[strings.LastIndex(typ, "/")+1:]
+			if name == "" {
+				return typ
+			}
+			return fmt.Sprintf("%s (%s)", name, typ)
+		}
+		return name
+	}
+	return ""
+}
+
+// sameListenAddr reports whether local, the local address of an accepted socket,
+// is served by the listen address lis: the same TCP port, Unix socket path or other name.
+func sameListenAddr(lis, local *channelzpb.Address) bool {
+	switch a := local.GetAddress().(type) {
+	case *channelzpb.Address_TcpipAddress:
+		l := lis.GetTcpipAddress()
+		return l != nil && l.Port == a.TcpipAddress.Port
+	case *channelzpb.Address_UdsAddress_:
+		l := lis.GetUdsAddress()
+		return l != nil && l.Filename == a.UdsAddress.Filename
+	case *channelzpb.Address_OtherAddress_:
+		l := lis.GetOtherAddress()
+		return l != nil && l.Name == a.OtherAddress.Name
+	}
+	return false
+}
+
+// SocketView is the JSON document of a socket, with its addresses formatted and its options unpacked.
+type SocketView struct {
+	*channelzpb.Socket
+	LocalAddress  string          `json:"local_address,omitempty"`
+	RemoteAddress string          `json:"remote_address,omitempty"`
+	Options       []*SocketOption `json:"options,omitempty"`
+}
+
+func newSocketView(socket *channelzpb.Socket) *SocketView {
+	return &SocketView{
+		Socket:        socket,
+		LocalAddress:  addrToString(socket.GetLocal()),
+		RemoteAddress: addrToString(socket.GetRemote()),
+		Options:       socketOptions(socket),
+	}
+}
+
+func newSocketViews(sockets []*channelzpb.Socket) []*SocketView {
+	var views []*SocketView
+	for _, socket := range sockets {
+		views = append(views, newSocketView(socket))
+	}
+	return views
+}
@@ -0,0 +1,103 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAddrToString(t *testing.T) {
+	tcp := func(ip net.IP, port int32) *channelzpb.Address {
+		return &channelzpb.Address{Address: &channelzpb.Address_TcpipAddress{
+			TcpipAddress: &channelzpb.Address_TcpIpAddress{IpAddress: ip, Port: port},
+		}}
+	}
+	other := func(name string, value *anypb.Any) *channelzpb.Address {
+		return &channelzpb.Address{Address: &channelzpb.Address_OtherAddress_{
+			OtherAddress: &channelzpb.Address_OtherAddress{Name: name, Value: value},
+		}}
+	}
+	empty, err := anypb.New(&emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		addr     *channelzpb.Address
+		expected string
+	}{
+		{nil, ""},
+		{tcp(net.IPv4(10, 0, 0, 1).To4(), 443), "10.0.0.1:443"},
+		{tcp(net.IPv4(10, 0, 0, 1), 443), "10.0.0.1:443"},
+		{tcp(net.ParseIP("2001:db8::1"), 8080), "[2001:db8::1]:8080"},
+		{tcp(nil, 9000), ":9000"},
+		{&channelzpb.Address{Address: &channelzpb.Address_UdsAddress_{
+			UdsAddress: &channelzpb.Address_UdsAddress{Filename: "/run/app.sock"},
+		}}, "unix:/run/app.sock"},
+		{other("bufconn", nil), "bufconn"},
+		{other("bufconn", empty), "bufconn (google.protobuf.Empty)"},
+		{other("", empty), "google.protobuf.Empty"},
+	} {
+		if actual := addrToString(tc.addr); actual != tc.expected {
+			t.Errorf("addrToString(%v) = %q, expected %q", tc.addr, actual, tc.expected)
+		}
+	}
+}
+
+func TestTreeServersUnix(t *testing.T) {
+	uds := &channelzpb.Address{Address: &channelzpb.Address_UdsAddress_{
+		UdsAddress: &channelzpb.Address_UdsAddress{Filename: "/run/app.sock"},
+	}}
+	lis := &channelzpb.Socket{Ref: &channelzpb.SocketRef{SocketId: 1}, Data: &channelzpb.SocketData{}, Local: uds}
+	accepted := &channelzpb.Socket{Ref: &channelzpb.SocketRef{SocketId: 2}, Data: &channelzpb.SocketData{}, Local: uds}
+	server := &channelzpb.Server{
+		Ref:          &channelzpb.ServerRef{ServerId: 1, Name: "uds"},
+		Data:         &channelzpb.ServerData{},
+		ListenSocket: []*channelzpb.SocketRef{lis.Ref},
+	}
+
+	b := &bytes.Buffer{}
+	c := &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			servers:       []*channelzpb.Server{server},
+			sockets:       []*channelzpb.Socket{lis, accepted},
+			serverSockets: map[int64][]*channelzpb.SocketRef{1: {accepted.Ref}},
+		},
+	}
+
+	expected := `
+ID: 1, Name: uds
+    [Calls]: Started:0 Succeeded:0, Failed:0, Last:none
+    [Socket] ID:1, Name:, RemoteName:, Local:unix:/run/app.sock
+        |-- [Socket] ID:2, Remote:<none>, Streams: Started:0, Succeeded:0, Failed:0, LastActivity:none
+`
+	if err := c.TreeServers(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+
+	b.Reset()
+	if err := c.TreeServers(&Options{Json: true}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var nodes []struct {
+		ListenSockets []struct {
+			Socket struct {
+				LocalAddress string `json:"local_address"`
+			} `json:"socket"`
+		} `json:"listen_sockets"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &nodes); err != nil {
+		t.Fatal(err)
+	}
+	if addr := nodes[0].ListenSockets[0].Socket.LocalAddress; addr != "unix:/run/app.sock" {
+		t.Errorf("unexpected local_address %q", addr)
+	}
+}
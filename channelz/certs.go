@@ -0,0 +1,182 @@
+package channelz
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// Certificate is the parsed view of a DER certificate of a TLS socket.
+type Certificate struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	SANs      []string  `json:"sans,omitempty"`
+	Serial    string    `json:"serial"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	// DaysLeft is rounded down: 0 expires within a day, -1 expired within the last day.
+	DaysLeft int    `json:"days_left"`
+	SHA256   string `json:"sha256"`
+}
+
+// parseCertificate parses a DER certificate, with the days left until its expiry at now.
+func parseCertificate(now time.Time, der []byte) (*Certificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	sum := sha256.Sum256(der)
+	fingerprint := make([]string, len(sum))
+	for i, b := range sum {
+		fingerprint[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return &Certificate{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		SANs:      sans,
+		Serial:    fmt.Sprintf("%X", cert.SerialNumber),
+		NotBefore: cert.NotBefore.UTC(),
+		NotAfter:  cert.NotAfter.UTC(),
+		DaysLeft:  daysLeft(now, cert.NotAfter),
+		SHA256:    strings.Join(fingerprint, ":"),
+	}, nil
+}
+
+// daysLeft returns the whole days from now to notAfter, rounded toward negative infinity
+// so that an expired certificate never shows 0 days.
+func daysLeft(now, notAfter time.Time) int {
+	return int(math.Floor(notAfter.Sub(now).Hours() / 24))
+}
+
+func tlsCipherName(tls *channelzpb.Security_Tls) string {
+	if name := tls.GetStandardName(); name != "" {
+		return name
+	}
+	return decorateEmpty(tls.GetOtherName())
+}
+
+func (cc *Client) describeCertificate(indent, title string, der []byte) {
+	if len(der) == 0 {
+		cc.printf("%s%s:\tnone\n", indent, title)
+		return
+	}
+
+	cc.printf("%s%s:\n", indent, title)
+	cert, err := parseCertificate(timeNow(), der)
+	if err != nil {
+		cc.printf("%s  Error:    \t%v\n", indent, err)
+		return
+	}
+	cc.printf("%s  Subject:  \t%s\n", indent, cert.Subject)
+	cc.printf("%s  Issuer:   \t%s\n", indent, cert.Issuer)
+	cc.printf("%s  SANs:     \t%s\n", indent, decorateEmpty(strings.Join(cert.SANs, ", ")))
+	cc.printf("%s  Serial:   \t%s\n", indent, cert.Serial)
+	cc.printf("%s  NotBefore:\t%s\n", indent, cert.NotBefore)
+	cc.printf("%s  NotAfter: \t%s\n", indent, cert.NotAfter)
+	cc.printf("%s  DaysLeft: \t%d\n", indent, cert.DaysLeft)
+	cc.printf("%s  SHA256:   \t%s\n", indent, cert.SHA256)
+}
+
+// CertRow is a certificate presented on a socket.
+type CertRow struct {
+	Socket *EntityRef `json:"socket"`
+	Owner  *EntityRef `json:"owner,omitempty"`
+	Local  string     `json:"local"`
+	Remote string     `json:"remote"`
+	// Side is "local" for the certificate of this process, "peer" for the remote one.
+	Side   string `json:"side"`
+	Cipher string `json:"cipher"`
+	*Certificate
+	Error string `json:"error,omitempty"`
+}
+
+// ListCerts prints the local and peer certificates of every TLS socket. With opts.ExpiringWithin,
+// only the certificates which expire within that duration, or already expired, are printed.
+func (cc *Client) ListCerts(opts *Options, ctx context.Context) error {
+	now := timeNow()
+
+	var rows []*CertRow
+	err := cc.visitSockets(ctx, func(socket *channelzpb.Socket, owner *EntityRef) error {
+		for _, row := range socketCertRows(now, socket, owner) {
+			if row.Certificate != nil && opts.ExpiringWithin > 0 && row.NotAfter.Sub(now) > opts.ExpiringWithin {
+				continue
+			}
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.document() {
+		if rows == nil {
+			rows = []*CertRow{}
+		}
+		return cc.encode(opts, rows)
+	}
+
+	cc.printf("Socket\tOwner\tRemote\tSide\tSubject\tSANs\tNotAfter\tDaysLeft\n")
+	for _, row := range rows {
+		cc.printf("%d\t%s\t%s\t%s\t", row.Socket.ID, entityRefString(row.Owner), decorateEmpty(row.Remote), row.Side)
+		if row.Certificate == nil {
+			cc.printf("error: %s\n", row.Error)
+			continue
+		}
+		cc.printf("%s\t%s\t%s\t%d\n", row.Subject, decorateEmpty(strings.Join(row.SANs, ",")),
+			row.NotAfter.Format(time.RFC3339), row.DaysLeft)
+	}
+	return nil
+}
+
+// socketCertRows returns the local and peer certificates of socket, none when it does not use TLS.
+func socketCertRows(now time.Time, socket *channelzpb.Socket, owner *EntityRef) []*CertRow {
+	tls := socket.GetSecurity().GetTls()
+	if tls == nil {
+		return nil
+	}
+
+	var rows []*CertRow
+	for _, side := range []struct {
+		name string
+		der  []byte
+	}{{"local", tls.LocalCertificate}, {"peer", tls.RemoteCertificate}} {
+		if len(side.der) == 0 {
+			continue
+		}
+
+		row := &CertRow{
+			Socket: &EntityRef{Kind: KindSocket, ID: socket.Ref.SocketId, Name: socket.Ref.Name},
+			Owner:  owner,
+			Local:  addrToString(socket.Local),
+			Remote: addrToString(socket.Remote),
+			Side:   side.name,
+			Cipher: tlsCipherName(tls),
+		}
+		cert, err := parseCertificate(now, side.der)
+		if err != nil {
+			row.Error = err.Error()
+		}
+		row.Certificate = cert
+		rows = append(rows, row)
+	}
+	return rows
+}
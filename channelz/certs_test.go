@@ -0,0 +1,167 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// newTestCertificate returns a self-signed DER certificate. The ed25519 key is derived from
+// a fixed seed and its signatures are deterministic, so the fingerprint is stable.
+func newTestCertificate(t *testing.T, cn string, serial int64, notAfter time.Time) []byte {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{byte(serial)}, ed25519.SeedSize))
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"test"}},
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.IPv4(10, 0, 0, 1)},
+		NotBefore:    fixedTime.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(nil, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func newTestCertsClient(t *testing.T, b *bytes.Buffer) *Client {
+	socket := testCreateSocket(socketParam{
+		localIP:    net.IPv4(127, 0, 1, 2),
+		localPort:  9443,
+		remoteIP:   net.IPv4(10, 0, 0, 1),
+		remotePort: 40000,
+	})
+	socket.Ref.SocketId = 100
+	socket.Ref.Name = "tls0"
+	socket.Security = &channelzpb.Security{
+		Model: &channelzpb.Security_Tls_{
+			Tls: &channelzpb.Security_Tls{
+				CipherSuite:       &channelzpb.Security_Tls_StandardName{StandardName: "TLS_AES_128_GCM_SHA256"},
+				LocalCertificate:  newTestCertificate(t, "server.test.com", 1, fixedTime.Add(10*24*time.Hour+time.Hour)),
+				RemoteCertificate: newTestCertificate(t, "client.test.com", 2, fixedTime.Add(100*24*time.Hour)),
+			},
+		},
+	}
+	server := &channelzpb.Server{
+		Ref:  &channelzpb.ServerRef{ServerId: 1, Name: "tls"},
+		Data: &channelzpb.ServerData{},
+	}
+	return &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			servers:       []*channelzpb.Server{server},
+			sockets:       []*channelzpb.Socket{socket},
+			serverSockets: map[int64][]*channelzpb.SocketRef{1: {socket.Ref}},
+		},
+	}
+}
+
+func TestListCerts(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestCertsClient(t, b)
+
+	expected := `
+Socket	Owner	Remote	Side	Subject	SANs	NotAfter	DaysLeft
+100	server/1	10.0.0.1:40000	local	CN=server.test.com,O=test	server.test.com,10.0.0.1	2018-12-11T22:33:20Z	10
+`
+	opts := &Options{ExpiringWithin: 30 * 24 * time.Hour}
+	if err := c.ListCerts(opts, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestDescribeSocketCertificates(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestCertsClient(t, b)
+
+	expected := `
+ID:       	100
+Name:     	tls0
+Local:    	127.0.1.2:9443
+Remote:   	10.0.0.1:40000
+Streams:
+  Started:    	0
+  Succeeded:  	0
+  Failed:     	0
+  LastCreated:	none
+Messages:
+  Sent:    	0
+  Recieved:  	0
+  LastSent:	none
+  LastReceived:	none
+Options:
+Security:
+  Model: tls
+  Cipher:	TLS_AES_128_GCM_SHA256
+  LocalCertificate:
+    Subject:  	CN=server.test.com,O=test
+    Issuer:   	CN=server.test.com,O=test
+    SANs:     	server.test.com, 10.0.0.1
+    Serial:   	1
+    NotBefore:	2018-11-30 21:33:20 +0000 UTC
+    NotAfter: 	2018-12-11 22:33:20 +0000 UTC
+    DaysLeft: 	10
+    SHA256:   	D8:6D:4F:39:0B:47:AB:56:6E:9B:0E:B4:0E:3F:C5:13:62:75:C4:12:76:91:42:D0:90:D7:96:25:6E:CD:CF:AF
+  RemoteCertificate:
+    Subject:  	CN=client.test.com,O=test
+    Issuer:   	CN=client.test.com,O=test
+    SANs:     	client.test.com, 10.0.0.1
+    Serial:   	2
+    NotBefore:	2018-11-30 21:33:20 +0000 UTC
+    NotAfter: 	2019-03-11 21:33:20 +0000 UTC
+    DaysLeft: 	99
+    SHA256:   	EB:5A:E9:4C:46:9B:34:1B:F6:BE:68:3F:70:CD:A0:B0:D3:C5:BB:A2:2E:9A:A2:CC:84:7B:9B:D7:22:10:10:BE
+`
+	if err := c.DescribeServerSocket(&Options{}, context.Background(), "100"); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"0.5d":  12 * time.Hour,
+		"90m":   90 * time.Minute,
+	} {
+		d, err := ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != expected {
+			t.Errorf("ParseDuration(%q) = %s, expected %s", s, d, expected)
+		}
+	}
+	if _, err := ParseDuration("xd"); err == nil {
+		t.Error("expected an error for xd")
+	}
+}
+
+func TestDaysLeft(t *testing.T) {
+	for _, tt := range []struct {
+		left time.Duration
+		want int
+	}{
+		{10*24*time.Hour + time.Hour, 10},
+		{23 * time.Hour, 0},
+		{0, 0},
+		{-time.Hour, -1},
+		{-24 * time.Hour, -1},
+		{-25 * time.Hour, -2},
+	} {
+		if got := daysLeft(fixedTime, fixedTime.Add(tt.left)); got != tt.want {
+			t.Errorf("daysLeft(%s) = %d, expected %d", tt.left, got, tt.want)
+		}
+	}
+}
@@ -2,11 +2,8 @@ package channelz
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"log"
-	"net"
 	"strconv"
 	"time"
 
@@ -36,23 +33,53 @@ func (cc *Client) printf(format string, a ...interface{}) {
 }
 
 func (cc *Client) DescribeServer(opts *Options, ctx context.Context, name string) error {
-	server := cc.findServer(ctx, name)
-	if server == nil {
+	servers, exact, err := cc.findServers(opts, ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
 		cc.printf("server %q not found", name)
 		return nil
 	}
 
-	if opts.Json {
-		return json.NewEncoder(cc.w).Encode(server)
+	if len(servers) > 1 {
+		if opts.Unique {
+			cc.printf("%s\t%s\n", "ID", "Name")
+			for _, server := range servers {
+				cc.printf("%d\t%s\n", server.Ref.ServerId, decorateEmpty(server.Ref.Name))
+			}
+			return fmt.Errorf("server %q matches %d servers", name, len(servers))
+		}
+		if exact {
+			opts.warnf("server name %q is ambiguous, describing all %d matches", name, len(servers))
+		}
 	}
 
+	// several matches are encoded as one array in JSON and YAML alike.
+	if opts.document() {
+		if len(servers) > 1 {
+			return cc.encode(opts, servers)
+		}
+		return cc.encode(opts, servers[0])
+	}
+	for i, server := range servers {
+		if i > 0 {
+			cc.printf("\n")
+		}
+		cc.describeServer(opts.palette(), server)
+	}
+
+	return nil
+}
+
+func (cc *Client) describeServer(p palette, server *channelzpb.Server) {
 	cc.printf("ID: \t%d\n", server.Ref.ServerId)
 	cc.printf("Name:\t%s\n", server.Ref.Name)
 
 	cc.printf("Calls:\n")
 	cc.printf("  Started:        \t%d\n", server.Data.CallsStarted)
 	cc.printf("  Succeeded:      \t%d\n", server.Data.CallsSucceeded)
-	cc.printf("  Failed:         \t%d\n", server.Data.CallsFailed)
+	cc.printf("  Failed:         \t%s\n", p.failedCount(server.Data.CallsFailed))
 	cc.printf("  LastCallStarted:\t%s\n", stringTimestamp(server.Data.LastCallStartedTimestamp))
 
 	if server.Data.Trace != nil {
@@ -65,66 +92,109 @@ func (cc *Client) DescribeServer(opts *Options, ctx context.Context, name string
 			cc.printf("    %s\t%-80s\t%s\n", "Severity", "Description", "Timestamp")
 			for _, ev := range server.Data.Trace.Events {
 				cc.printf("    %s\t%-80s\t%s\n",
-					prettyChannelTraceEventSeverity(ev.Severity), ev.Description, stringTimestamp(ev.Timestamp))
+					p.severity(prettyChannelTraceEventSeverity(ev.Severity)), ev.Description, stringTimestamp(ev.Timestamp))
 			}
 		}
 	}
-
-	return nil
 }
 
-func (cc *Client) findServer(ctx context.Context, name string) *channelzpb.Server {
-	n, err := strconv.Atoi(name)
+// findServers returns the servers matching name, which is an ID, an exact name, or a pattern.
+// exact reports whether name was matched literally, so multiple matches are ambiguous.
+func (cc *Client) findServers(opts *Options, ctx context.Context, name string) (found []*channelzpb.Server, exact bool, err error) {
+	if n, err := strconv.Atoi(name); err == nil && !opts.Regex {
+		server, err := cc.findServerByID(ctx, int64(n))
+		if err != nil {
+			return nil, false, err
+		}
+		if server != nil {
+			found = append(found, server)
+		}
+		return found, true, nil
+	}
+
+	m, err := newNameMatcher(name, opts.Regex)
 	if err != nil {
-		return cc.findServerByName(ctx, name)
+		return nil, false, err
 	}
-	return cc.findServerByID(ctx, int64(n))
-}
 
-func (cc *Client) findServerByName(ctx context.Context, name string) *channelzpb.Server {
-	var found *channelzpb.Server
-	cc.visitGetServers(ctx, func(server *channelzpb.Server) {
-		if server.Ref.Name == name {
-			if found == nil {
-				found = server
-			}
+	err = cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
+		if m.Match(server.Ref.Name) {
+			found = append(found, server)
 		}
+		return nil
 	})
+	if err != nil {
+		return nil, false, err
+	}
 
-	return found
+	return found, m.exact, nil
 }
 
-func (cc *Client) findServerByID(ctx context.Context, id int64) *channelzpb.Server {
+func (cc *Client) findServerByID(ctx context.Context, id int64) (*channelzpb.Server, error) {
 	var found *channelzpb.Server
-	cc.visitGetServers(ctx, func(server *channelzpb.Server) {
+	err := cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
 		if server.Ref.ServerId == id {
 			found = server
 		}
+		return nil
 	})
 
-	return found
+	return found, err
 }
 
 func (cc *Client) DescribeChannel(opts *Options, ctx context.Context, name string) error {
-	channel := cc.findTopChannel(ctx, name)
-	if channel == nil {
+	channels, exact, err := cc.findTopChannels(opts, ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
 		cc.printf("channel %q not found", name)
 		return nil
 	}
 
-	if opts.Json {
-		return json.NewEncoder(cc.w).Encode(channel)
+	if len(channels) > 1 {
+		if opts.Unique {
+			cc.printf("%s\t%s\t%s\t%s\n", "ID", "Name", "State", "Target")
+			for _, channel := range channels {
+				cc.printf("%d\t%s\t%s\t%s\n", channel.Ref.ChannelId, decorateEmpty(channel.Ref.Name),
+					channel.Data.State.State.String(), channel.Data.Target)
+			}
+			return fmt.Errorf("channel %q matches %d channels", name, len(channels))
+		}
+		if exact {
+			opts.warnf("channel name %q is ambiguous, describing all %d matches", name, len(channels))
+		}
+	}
+
+	// several matches are encoded as one array in JSON and YAML alike.
+	if opts.document() {
+		if len(channels) > 1 {
+			return cc.encode(opts, channels)
+		}
+		return cc.encode(opts, channels[0])
+	}
+	for i, channel := range channels {
+		if i > 0 {
+			cc.printf("\n")
+		}
+		if err := cc.describeChannel(ctx, opts.palette(), channel); err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+func (cc *Client) describeChannel(ctx context.Context, p palette, channel *channelzpb.Channel) error {
 	cc.printf("ID:       \t%d\n", channel.Ref.ChannelId)
 	cc.printf("Name:     \t%s\n", channel.Ref.Name)
-	cc.printf("State:    \t%s\n", channel.Data.State.State.String())
+	cc.printf("State:    \t%s\n", p.state(channel.Data.State.State.String()))
 	cc.printf("Target:   \t%s\n", channel.Data.Target)
 
 	cc.printf("Calls:\n")
 	cc.printf("  Started:    \t%d\n", channel.Data.CallsStarted)
 	cc.printf("  Succeeded:  \t%d\n", channel.Data.CallsSucceeded)
-	cc.printf("  Failed:     \t%d\n", channel.Data.CallsFailed)
+	cc.printf("  Failed:     \t%s\n", p.failedCount(channel.Data.CallsFailed))
 	cc.printf("  LastCallStarted:\t%s\n", stringTimestamp(channel.Data.LastCallStartedTimestamp))
 
 	if len(channel.SocketRef) == 0 {
@@ -155,15 +225,15 @@ func (cc *Client) DescribeChannel(opts *Options, ctx context.Context, name strin
 		for _, subchref := range channel.SubchannelRef {
 			res, err := cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: subchref.SubchannelId})
 			if err != nil {
-				log.Fatalf("err %v", err)
+				return err
 			}
 
 			subch := res.Subchannel
-			cc.printf("  %d\t%s\t%s\t%-6d\t%-8d\t%-6d\n",
-				subch.Ref.SubchannelId, subch.Ref.Name, subch.Data.State.State.String(),
+			cc.printf("  %d\t%s\t%s\t%-6d\t%-8d\t%s\n",
+				subch.Ref.SubchannelId, subch.Ref.Name, p.state(subch.Data.State.State.String()),
 				subch.Data.CallsStarted,
 				subch.Data.CallsSucceeded,
-				subch.Data.CallsFailed,
+				p.failed(float64(subch.Data.CallsFailed), fmt.Sprintf("%-6d", subch.Data.CallsFailed)),
 			)
 		}
 	}
@@ -178,23 +248,48 @@ func (cc *Client) DescribeChannel(opts *Options, ctx context.Context, name strin
 			cc.printf("    %s\t%-80s\t%s\n", "Severity", "Description", "Timestamp")
 			for _, ev := range channel.Data.Trace.Events {
 				cc.printf("    %s\t%-80s\t%s\n",
-					prettyChannelTraceEventSeverity(ev.Severity), ev.Description, stringTimestamp(ev.Timestamp))
+					p.severity(prettyChannelTraceEventSeverity(ev.Severity)), ev.Description, stringTimestamp(ev.Timestamp))
 			}
 		}
 	}
+
 	return nil
 }
 
-func (cc *Client) findSocketByID(ctx context.Context, id int64) *channelzpb.Socket {
+func (cc *Client) describeSubchannel(p palette, subch *channelzpb.Subchannel) {
+	cc.printf("ID:       \t%d\n", subch.Ref.SubchannelId)
+	cc.printf("Name:     \t%s\n", subch.Ref.Name)
+	cc.printf("State:    \t%s\n", p.state(subch.Data.State.State.String()))
+	cc.printf("Target:   \t%s\n", subch.Data.Target)
+
+	cc.printf("Calls:\n")
+	cc.printf("  Started:    \t%d\n", subch.Data.CallsStarted)
+	cc.printf("  Succeeded:  \t%d\n", subch.Data.CallsSucceeded)
+	cc.printf("  Failed:     \t%s\n", p.failedCount(subch.Data.CallsFailed))
+	cc.printf("  LastCallStarted:\t%s\n", stringTimestamp(subch.Data.LastCallStartedTimestamp))
+
+	if len(subch.SocketRef) == 0 {
+		cc.printf("Socket:   \t%s\n", "<none>")
+	} else {
+		cc.printf("  Sockets\n")
+		cc.printf("    %s\t%s\n", "SocketID", "Name")
+		for _, socket := range subch.SocketRef {
+			cc.printf("    %d\t%s\t\n", socket.SocketId, socket.Name)
+		}
+	}
+}
+
+// findSocketByID returns the socket with the given ID, or nil if there is none.
+func (cc *Client) findSocketByID(ctx context.Context, id int64) (*channelzpb.Socket, error) {
 	res, err := cc.cc.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: id})
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return nil
+			return nil, nil
 		}
-		log.Fatalf("err: %v\n", err)
+		return nil, err
 	}
 
-	return res.Socket
+	return res.Socket, nil
 }
 
 func (cc *Client) DescribeServerSocket(opts *Options, ctx context.Context, name string) error {
@@ -204,16 +299,24 @@ func (cc *Client) DescribeServerSocket(opts *Options, ctx context.Context, name
 		return nil
 	}
 
-	socket := cc.findSocketByID(ctx, id)
+	socket, err := cc.findSocketByID(ctx, id)
+	if err != nil {
+		return err
+	}
 	if socket == nil {
 		cc.printf("serversocket %q not found", name)
 		return nil
 	}
 
-	if opts.Json {
-		return json.NewEncoder(cc.w).Encode(socket)
+	if opts.document() {
+		return cc.encode(opts, newSocketView(socket))
 	}
 
+	cc.describeSocket(opts.palette(), socket)
+	return nil
+}
+
+func (cc *Client) describeSocket(p palette, socket *channelzpb.Socket) {
 	cc.printf("ID:       \t%d\n", socket.Ref.SocketId)
 	cc.printf("Name:     \t%s\n", socket.Ref.Name)
 	cc.printf("Local:    \t%s\n", addrToString(socket.Local))
@@ -222,7 +325,7 @@ func (cc *Client) DescribeServerSocket(opts *Options, ctx context.Context, name
 	cc.printf("Streams:\n")
 	cc.printf("  Started:    \t%d\n", socket.Data.StreamsStarted)
 	cc.printf("  Succeeded:  \t%d\n", socket.Data.StreamsSucceeded)
-	cc.printf("  Failed:     \t%d\n", socket.Data.StreamsFailed)
+	cc.printf("  Failed:     \t%s\n", p.failedCount(socket.Data.StreamsFailed))
 	cc.printf("  LastCreated:\t%s\n", stringTimestamp(socket.Data.LastRemoteStreamCreatedTimestamp))
 
 	cc.printf("Messages:\n")
@@ -232,181 +335,51 @@ func (cc *Client) DescribeServerSocket(opts *Options, ctx context.Context, name
 	cc.printf("  LastReceived:\t%s\n", stringTimestamp(socket.Data.LastMessageReceivedTimestamp))
 
 	cc.printf("Options:\n")
-	for _, opt := range socket.Data.Option {
-		cc.printf("  %s:\t%s\n", opt.Name, opt.Value)
+	for _, opt := range socketOptions(socket) {
+		cc.describeSocketOption("  ", opt)
 	}
 
 	cc.printf("Security:\n")
 	if socket.Security == nil {
 		cc.printf("  Model: none\n")
 	} else {
-		switch socket.Security.GetModel().(type) {
+		switch model := socket.Security.GetModel().(type) {
 		case *channelzpb.Security_Tls_:
 			cc.printf("  Model: tls\n")
+			cc.printf("  Cipher:\t%s\n", tlsCipherName(model.Tls))
+			cc.describeCertificate("  ", "LocalCertificate", model.Tls.LocalCertificate)
+			cc.describeCertificate("  ", "RemoteCertificate", model.Tls.RemoteCertificate)
 		case *channelzpb.Security_Other:
 			cc.printf("  Model: other\n")
 		}
 	}
-
-	return nil
-}
-
-func (cc *Client) ListServers(opts *Options, ctx context.Context) error {
-	now := timeNow()
-
-	if !opts.Json {
-		cc.printf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			"ID", "Name", "LocalAddr", "Calls", "Success", "Fail", "LastCall")
-	}
-
-	cc.visitGetServers(ctx, func(server *channelzpb.Server) {
-		// see first socket only
-		var socket *channelzpb.Socket
-		if len(server.ListenSocket) > 0 {
-			res, err := cc.cc.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: server.ListenSocket[0].SocketId})
-			if err != nil {
-				log.Fatalf("err %v\n", err)
-			}
-			socket = res.Socket
-		}
-
-		var localAddr string
-		if addr := socket.GetLocal().GetTcpipAddress(); addr != nil {
-			localAddr = fmt.Sprintf("[%v]:%v", net.IP(addr.IpAddress).String(), addr.Port)
-		}
-
-		if opts.Json {
-			_ = json.NewEncoder(cc.w).Encode(server)
-			return
-		}
-
-		cc.printf("%d\t%s\t%-12s\t%-6d\t%-6d\t%-6d\t%s\n",
-			server.Ref.ServerId,
-			decorateEmpty(server.Ref.Name),
-			decorateEmpty(localAddr),
-			server.Data.CallsStarted,
-			server.Data.CallsSucceeded,
-			server.Data.CallsFailed,
-			elapsedTimestamp(now, server.Data.LastCallStartedTimestamp),
-		)
-	})
-
-	return nil
 }
 
-func (cc *Client) TreeServers(ctx context.Context) {
-	now := timeNow()
-	cc.visitGetServers(ctx, func(server *channelzpb.Server) {
-		cc.printf("ID: %v, Name: %v\n", server.Ref.ServerId, server.Ref.Name)
-
-		elapesed := elapsedTimestamp(now, server.Data.LastCallStartedTimestamp)
-		cc.printf("    [Calls]: Started:%v Succeeded:%v, Failed:%v, Last:%s\n", server.Data.CallsStarted, server.Data.CallsSucceeded, server.Data.CallsFailed, elapesed)
-
-		for _, socket := range server.ListenSocket {
-			res, err := cc.cc.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: socket.SocketId})
-			if err != nil {
-				log.Fatalf("err %v\n", err)
-			}
-
-			socket := res.Socket
-			if socket == nil {
-				cc.printf("not found\n")
-				continue
-			}
-			cc.printf("    [Socket] ID:%v, Name:%v, RemoteName:%v", socket.Ref.SocketId, socket.Ref.Name, socket.RemoteName)
-			if addr := socket.Local.GetTcpipAddress(); addr != nil {
-				cc.printf(", Local IP:%v, Port:%v", net.IP(addr.IpAddress).String(), addr.Port)
-			}
-			cc.printf("\n")
-		}
-
-		cc.printf("\n")
-	})
-}
-
-func (cc *Client) visitGetServers(ctx context.Context, fn func(*channelzpb.Server)) {
+func (cc *Client) visitGetServers(ctx context.Context, fn func(*channelzpb.Server) error) error {
 	lastServerID := int64(0)
 	for {
 		res, err := cc.cc.GetServers(ctx, &channelzpb.GetServersRequest{StartServerId: lastServerID})
 		if err != nil {
-			log.Fatalf("err: %v\n", err)
+			return err
 		}
 
 		for _, server := range res.Server {
-			fn(server)
+			if err := fn(server); err != nil {
+				return err
+			}
+			if id := server.GetRef().GetServerId(); id > lastServerID {
+				lastServerID = id
+			}
 		}
-		if res.End {
-			break
+		if res.End || len(res.Server) == 0 {
+			return nil
 		}
 
 		lastServerID++
 	}
 }
 
-func (cc *Client) ListTopChannels(opts *Options, ctx context.Context) error {
-	now := timeNow()
-
-	if !opts.Json {
-		cc.printf("%s\t%-80s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			"ID", "Name", "State", "Channel", "SubChannel", "Calls", "Success", "Fail", "LastCall")
-	}
-
-	cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) {
-		if opts.Json {
-			_ = json.NewEncoder(cc.w).Encode(channel)
-			return
-		}
-
-		cc.printf("%d\t%-80s\t%s\t%-7d\t%-10d\t%-6d\t%-6d\t%-6d\t%-8s\n",
-			channel.Ref.ChannelId,
-			decorateEmpty(channel.Ref.Name),
-			channel.Data.State.State.String(),
-			len(channel.ChannelRef),
-			len(channel.SubchannelRef),
-			channel.Data.CallsStarted,
-			channel.Data.CallsSucceeded,
-			channel.Data.CallsFailed,
-			elapsedTimestamp(now, channel.Data.LastCallStartedTimestamp),
-		)
-	})
-	return nil
-}
-
-func addrToString(addr *channelzpb.Address) string {
-	if tcpaddr := addr.GetTcpipAddress(); tcpaddr != nil {
-		return fmt.Sprintf("[%v]:%v", net.IP(tcpaddr.IpAddress).String(), tcpaddr.Port)
-	}
-	return ""
-}
-
-func (cc *Client) ListServerSockets(ctx context.Context) {
-	now := timeNow()
-
-	cc.printf("%s\t%s\t%-40s\t%-20s\t%-20s\t%-20s\t%s\t%s\t%s\t%s\n",
-		"ID", "ServerID", "Name", "RemoteName", "Local", "Remote", "Started", "Success", "Fail", "LastStream")
-
-	cc.visitGetServers(ctx, func(server *channelzpb.Server) {
-		cc.visitGetServerSockets(ctx, server.Ref.ServerId, func(socket *channelzpb.Socket) {
-			localIP := addrToString(socket.Local)
-			remoteIP := addrToString(socket.Remote)
-
-			cc.printf("%d\t%-8d\t%-40s\t%-20s\t%-16s\t%-16s\t%-6d\t%-6d\t%-6d\t%-8s\n",
-				socket.Ref.SocketId,
-				server.Ref.ServerId,
-				decorateEmpty(socket.Ref.Name),
-				decorateEmpty(socket.RemoteName),
-				decorateEmpty(localIP),
-				decorateEmpty(remoteIP),
-				socket.Data.StreamsStarted,
-				socket.Data.StreamsSucceeded,
-				socket.Data.StreamsFailed,
-				elapsedTimestamp(now, socket.Data.LastRemoteStreamCreatedTimestamp),
-			)
-		})
-	})
-}
-
-func (cc *Client) visitGetServerSockets(ctx context.Context, id int64, fn func(*channelzpb.Socket)) {
+func (cc *Client) visitGetServerSockets(ctx context.Context, id int64, fn func(*channelzpb.Socket) error) error {
 	lastSocketID := int64(0)
 	for {
 		res, err := cc.cc.GetServerSockets(ctx, &channelzpb.GetServerSocketsRequest{
@@ -414,135 +387,84 @@ func (cc *Client) visitGetServerSockets(ctx context.Context, id int64, fn func(*
 			StartSocketId: lastSocketID,
 		})
 		if err != nil {
-			log.Fatalf("err: %v\n", err)
+			return err
 		}
 
 		for _, ref := range res.SocketRef {
 			socket, err := cc.cc.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: ref.SocketId})
 			if err != nil {
-				log.Fatalf("err %v\n", err)
+				return err
 			}
 
-			fn(socket.Socket)
+			if err := fn(socket.Socket); err != nil {
+				return err
+			}
+			if ref.SocketId > lastSocketID {
+				lastSocketID = ref.SocketId
+			}
 		}
-		if res.End {
-			break
+		if res.End || len(res.SocketRef) == 0 {
+			return nil
 		}
 
 		lastSocketID++
 	}
 }
 
-func (cc *Client) TreeTopChannels(ctx context.Context) {
-	now := timeNow()
-
-	cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) {
-		cc.printf("%s (ID:%d) [%s]\n",
-			channel.Data.Target, channel.Ref.ChannelId,
-			channel.Data.State.State.String())
-
-		elapesed := elapsedTimestamp(now, channel.Data.LastCallStartedTimestamp)
-		cc.printf("  [Calls] Started:%v, Succeeded:%v, Failed:%v, Last:%v\n", channel.Data.CallsStarted, channel.Data.CallsSucceeded, channel.Data.CallsFailed, elapesed)
-
-		// for _, ev := range channel.Data.Trace.Events {
-		// 	cc.printf("ev %v\n", ev)
-		// }
-
-		for _, socket := range channel.SocketRef {
-			cc.printf("socket %v\n", socket)
-		}
-
-		for _, ch := range channel.ChannelRef {
-			cc.printf("ch %v\n", ch)
-		}
-
-		if len(channel.SubchannelRef) != 0 {
-			cc.printf("  [Subchannels]\n")
+// findTopChannels returns the top channels matching name, which is an ID, an exact name or target, or a pattern.
+// exact reports whether name was matched literally, so multiple matches are ambiguous.
+func (cc *Client) findTopChannels(opts *Options, ctx context.Context, name string) (found []*channelzpb.Channel, exact bool, err error) {
+	if n, err := strconv.Atoi(name); err == nil && !opts.Regex {
+		channel, err := cc.findTopChannelByID(ctx, int64(n))
+		if err != nil {
+			return nil, false, err
 		}
-		for _, ch := range channel.SubchannelRef {
-			res, err := cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: ch.SubchannelId})
-			if err != nil {
-				log.Fatalf("err %v", err)
-			}
-
-			subch := res.Subchannel
-			cc.printf("    |-- %s (ID:%d) [%s]\n",
-				subch.Data.Target, subch.Ref.SubchannelId,
-				subch.Data.State.State.String())
-
-			elapesed := elapsedTimestamp(now, subch.Data.LastCallStartedTimestamp)
-			cc.printf("          [Calls]: Started:%v, Succeeded:%v, Failed:%v, Last:%s\n", subch.Data.CallsStarted, subch.Data.CallsSucceeded, subch.Data.CallsFailed, elapesed)
-
-			for _, socket := range subch.SocketRef {
-				res, err := cc.cc.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: socket.SocketId})
-				if err != nil {
-					log.Fatalf("err %v\n", err)
-				}
-
-				socket := res.Socket
-				cc.printf("          [Socket] ID:%v, Name:%v, RemoteName:%v", socket.Ref.SocketId, socket.Ref.Name, socket.RemoteName)
-				cc.printf(", Local:")
-				if addr := socket.Local.GetTcpipAddress(); addr != nil {
-					cc.printf("[%v]:%v", net.IP(addr.IpAddress).String(), addr.Port)
-				}
-				cc.printf(" Remote:")
-				if addr := socket.Remote.GetTcpipAddress(); addr != nil {
-					cc.printf("[%v]:%v", net.IP(addr.IpAddress).String(), addr.Port)
-				}
-				cc.printf("\n")
-			}
-
-			for _, ch := range subch.ChannelRef {
-				cc.printf("---- ch %v\n", ch)
-			}
-			for _, ch := range subch.SubchannelRef {
-				cc.printf("---- ch %v\n", ch)
-			}
+		if channel != nil {
+			found = append(found, channel)
 		}
+		return found, true, nil
+	}
 
-		cc.printf("\n")
-	})
-}
-
-func (cc *Client) findTopChannel(ctx context.Context, name string) *channelzpb.Channel {
-	n, err := strconv.Atoi(name)
+	m, err := newNameMatcher(name, opts.Regex)
 	if err != nil {
-		return cc.findTopChannelByName(ctx, name)
+		return nil, false, err
 	}
-	return cc.findTopChannelByID(ctx, int64(n))
-}
 
-func (cc *Client) findTopChannelByName(ctx context.Context, name string) *channelzpb.Channel {
-	var found *channelzpb.Channel
-	cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) {
-		if channel.Ref.Name == name {
-			if found == nil {
-				found = channel
-			}
+	err = cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
+		if m.Match(channel.Ref.Name, channel.Data.Target) {
+			found = append(found, channel)
 		}
+		return nil
 	})
+	if err != nil {
+		return nil, false, err
+	}
 
-	return found
+	return found, m.exact, nil
 }
 
-func (cc *Client) findTopChannelByID(ctx context.Context, id int64) *channelzpb.Channel {
+func (cc *Client) findTopChannelByID(ctx context.Context, id int64) (*channelzpb.Channel, error) {
 	var found *channelzpb.Channel
-	cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) {
+	err := cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
 		if channel.Ref.ChannelId == id {
 			found = channel
 		}
+		return nil
 	})
 
-	return found
+	return found, err
 }
 
-func (cc *Client) visitTopChannels(ctx context.Context, fn func(*channelzpb.Channel)) {
+// maxTopChannelsRetries is the number of times a GetTopChannels which timed out is retried.
+const maxTopChannelsRetries = 2
+
+func (cc *Client) visitTopChannels(ctx context.Context, fn func(*channelzpb.Channel) error) error {
 	lastChannelID := int64(0)
 	retry := 0
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return ctx.Err()
 		default:
 		}
 
@@ -551,18 +473,27 @@ func (cc *Client) visitTopChannels(ctx context.Context, fn func(*channelzpb.Chan
 		cancel()
 
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// retry the requests which timed out a few times, each with a longer timeout.
+			if status.Code(err) != codes.DeadlineExceeded || retry >= maxTopChannelsRetries {
+				return err
+			}
 			retry++
 			continue
 		}
 
 		for _, channel := range res.Channel {
-			fn(channel)
+			if err := fn(channel); err != nil {
+				return err
+			}
 			if id := channel.GetRef().GetChannelId(); id > lastChannelID {
 				lastChannelID = id
 			}
 		}
 		if res.End {
-			break
+			return nil
 		}
 
 		lastChannelID++
@@ -3,8 +3,11 @@ package channelz
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
 )
 
 func newTestClient1(b *bytes.Buffer) *Client {
@@ -145,6 +148,41 @@ Trace:
 	})
 }
 
+func TestDescribeChannelPattern(t *testing.T) {
+	b := &bytes.Buffer{}
+	ctx := context.Background()
+	c := newTestClient1(b)
+
+	t.Run("Glob", func(t *testing.T) {
+		b.Reset()
+		_ = c.DescribeChannel(&Options{}, ctx, "foo*")
+		if n := strings.Count(b.String(), "Name:     \tfoo"); n != 2 {
+			t.Errorf("expected 2 channels described, got %d:\n%s", n, b.String())
+		}
+	})
+
+	t.Run("Regex", func(t *testing.T) {
+		b.Reset()
+		_ = c.DescribeChannel(&Options{Regex: true}, ctx, "^foo1\\.test")
+		if n := strings.Count(b.String(), "Name:     \tfoo1"); n != 1 {
+			t.Errorf("expected foo1 described, got:\n%s", b.String())
+		}
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		expected := `
+ID	Name	State	Target
+0	foo0	READY	foo0.test.com
+1	foo1	READY	foo1.test.com
+`
+		b.Reset()
+		if err := c.DescribeChannel(&Options{Unique: true}, ctx, "foo*"); err == nil {
+			t.Error("expected error for ambiguous name")
+		}
+		assertOutput(t, expected, b.String())
+	})
+}
+
 func TestListServers(t *testing.T) {
 	b := &bytes.Buffer{}
 	ctx := context.Background()
@@ -152,14 +190,27 @@ func TestListServers(t *testing.T) {
 
 	t.Run("server", func(t *testing.T) {
 		expected := `
-ID	Name	LocalAddr	Calls	Success	Fail	LastCall
-0	server0	[127.0.1.2]:9000	100   	90    	10    	none
-1	server1	[127.0.1.2]:9001	110   	99    	11    	0ms
+ID  Name     LocalAddr       Calls  Success  Fail  LastCall
+0   server0  127.0.1.2:9000  100    90       10    none
+1   server1  127.0.1.2:9001  110    99       11    0ms
 `
 		b.Reset()
-		_ = c.ListServers(&Options{}, ctx)
+		_ = c.List(&Options{}, ctx, KindServer)
 		assertOutput(t, expected, b.String())
 	})
+
+	t.Run("paged", func(t *testing.T) {
+		paged := &fakeChannelzClient{serverPage: 1}
+		for _, id := range []int64{0, 5, 9} {
+			paged.servers = append(paged.servers, &channelzpb.Server{
+				Ref:  &channelzpb.ServerRef{ServerId: id, Name: fmt.Sprintf("server%d", id)},
+				Data: &channelzpb.ServerData{},
+			})
+		}
+		b.Reset()
+		_ = (&Client{w: b, cc: paged}).List(&Options{Format: "go-template={{range .}}{{.Ref.Name}} {{end}}"}, ctx, KindServer)
+		assertOutput(t, "server0 server5 server9", b.String())
+	})
 }
 
 func TestListChannels(t *testing.T) {
@@ -169,12 +220,12 @@ func TestListChannels(t *testing.T) {
 
 	t.Run("server", func(t *testing.T) {
 		expected := `
-ID	Name                                                                            	State	Channel	SubChannel	Calls	Success	Fail	LastCall
-0	foo0                                                                            	READY	0      	1         	100   	90    	10    	0ms     
-1	foo1                                                                            	READY	0      	4         	110   	99    	11    	0ms
+ID  Name  State  Channel  SubChannel  Calls  Success  Fail  LastCall
+0   foo0  READY  0        1           100    90       10    0ms
+1   foo1  READY  0        4           110    99       11    0ms
 `
 		b.Reset()
-		_ = c.ListTopChannels(&Options{}, ctx)
+		_ = c.List(&Options{}, ctx, KindChannel)
 		assertOutput(t, expected, b.String())
 	})
 }
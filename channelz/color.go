@@ -0,0 +1,99 @@
+package channelz
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Modes of the --color flag.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ParseColor validates a --color mode, auto when empty.
+func ParseColor(s string) (string, error) {
+	switch s {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid color %q, expected %s, %s or %s", s, ColorAuto, ColorAlways, ColorNever)
+}
+
+// ANSI colors.
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// palette colors the text of the tables, trees and descriptions, when enabled.
+type palette bool
+
+// newPalette returns the palette of mode for w: with auto, colors are enabled when w is a
+// terminal and NO_COLOR is not set.
+func newPalette(mode string, w io.Writer) palette {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return palette(isTerminal(w))
+}
+
+func (p palette) paint(color, s string) string {
+	if !p || color == "" || s == "" {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+// state colors a connectivity state: READY green, TRANSIENT_FAILURE red, CONNECTING and IDLE yellow.
+func (p palette) state(s string) string {
+	switch s {
+	case "READY":
+		return p.paint(colorGreen, s)
+	case "TRANSIENT_FAILURE":
+		return p.paint(colorRed, s)
+	case "CONNECTING", "IDLE":
+		return p.paint(colorYellow, s)
+	}
+	return s
+}
+
+// failed colors s, the text of a failure count or rate, red when the count is not zero.
+func (p palette) failed(n float64, s string) string {
+	if n == 0 {
+		return s
+	}
+	return p.paint(colorRed, s)
+}
+
+// failedCount formats a failure count, red when it is not zero.
+func (p palette) failedCount(n int64) string {
+	return p.failed(float64(n), strconv.FormatInt(n, 10))
+}
+
+// severity colors a severity from prettyChannelTraceEventSeverity: ERROR red, WARNING yellow.
+func (p palette) severity(s string) string {
+	switch s {
+	case "ERROR":
+		return p.paint(colorRed, s)
+	case "WARNING":
+		return p.paint(colorYellow, s)
+	}
+	return s
+}
+
+// ansiEscape matches the color sequences of paint.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
@@ -0,0 +1,101 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// showColors replaces the color codes of s with readable tags.
+var showColors = strings.NewReplacer(
+	"\x1b[31m", "<red>",
+	"\x1b[32m", "<green>",
+	"\x1b[33m", "<yellow>",
+	"\x1b[0m", "</>",
+)
+
+func TestListColors(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.List(&Options{Color: ColorAlways}, context.Background(), KindChannel); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cells are aligned by their visible width.
+	assertOutput(t, `
+ID  Name  State  Channel  SubChannel  Calls  Success  Fail  LastCall
+0   foo0  <green>READY</>  0        1           100    90       <red>10</>    0ms
+1   foo1  <green>READY</>  0        4           110    99       <red>11</>    0ms
+`, showColors.Replace(b.String()))
+}
+
+func TestListColorsNever(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	for _, color := range []string{ColorNever, ColorAuto} {
+		b.Reset()
+		if err := c.List(&Options{Color: color}, context.Background(), KindChannel); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(b.String(), "\x1b[") {
+			t.Errorf("--color=%s: unexpected colors in\n%s", color, b.String())
+		}
+	}
+}
+
+func TestTreeColors(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.TreeServers(&Options{Color: ColorAlways, MaxSockets: 1}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+ID: 0, Name: server0
+    [Calls]: Started:100 Succeeded:90, Failed:<red>10</>, Last:none
+    [Socket] ID:0, Name:sock0, RemoteName:, Local:127.0.1.2:9000
+
+ID: 1, Name: server1
+    [Calls]: Started:110 Succeeded:99, Failed:<red>11</>, Last:0ms
+    [Socket] ID:1, Name:sock1, RemoteName:, Local:127.0.1.2:9001
+        |-- [Socket] ID:7, Remote:10.0.0.1:40000, Streams: Started:10, Succeeded:9, Failed:<red>1</>, LastActivity:0ms
+        ... and 2 more
+`, showColors.Replace(b.String()))
+}
+
+func TestEventsColors(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestEventsClient(b)
+	if err := c.ListEvents(&Options{Color: ColorAlways}, context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	out := showColors.Replace(b.String())
+	if !strings.Contains(out, "\t<yellow>WARNING</>\tsubchannel 200") {
+		t.Errorf("expected a yellow WARNING in\n%s", out)
+	}
+	if strings.Contains(out, "<yellow>INFO") || strings.Contains(out, "<red>INFO") {
+		t.Errorf("unexpected colored INFO in\n%s", out)
+	}
+}
+
+func TestNewPalette(t *testing.T) {
+	b := &bytes.Buffer{}
+	t.Setenv("NO_COLOR", "")
+	if newPalette(ColorAuto, b) {
+		t.Error("auto: expected no colors when not writing to a terminal")
+	}
+	if !newPalette(ColorAlways, b) {
+		t.Error("always: expected colors")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !newPalette(ColorAlways, b) {
+		t.Error("always: expected colors with NO_COLOR")
+	}
+
+	if _, err := ParseColor("rainbow"); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
@@ -0,0 +1,259 @@
+package channelz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Changes of an entity between two snapshots.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// EntityChange is the change of one entity between two snapshots.
+// The counter deltas are stream deltas for sockets.
+type EntityChange struct {
+	Change string `json:"change"`
+	Kind   string `json:"kind"`
+	ID     int64  `json:"id"`
+	// BeforeID is the ID in the first snapshot when the entity was matched by target or address.
+	BeforeID    int64  `json:"before_id,omitempty"`
+	MatchedBy   string `json:"matched_by,omitempty"`
+	Name        string `json:"name,omitempty"`
+	StateBefore string `json:"state_before,omitempty"`
+	State       string `json:"state,omitempty"`
+	Started     int64  `json:"started_delta"`
+	Succeeded   int64  `json:"succeeded_delta"`
+	Failed      int64  `json:"failed_delta"`
+}
+
+// DiffReport is the change report between two snapshots.
+type DiffReport struct {
+	Before  time.Time       `json:"before"`
+	After   time.Time       `json:"after"`
+	Changes []*EntityChange `json:"changes"`
+}
+
+// ReadSnapshot reads a snapshot written by the snapshot command, as JSON or YAML.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{}
+	if json.Valid(data) {
+		return s, json.Unmarshal(data, s)
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(v); err != nil {
+		return nil, err
+	}
+	return s, json.Unmarshal(data, s)
+}
+
+// DiffSnapshots compares two snapshots. Entities are matched by ID and, failing that, by target
+// for channels and subchannels and by address pair for servers and sockets. Entities without
+// state or counter changes are left out.
+func DiffSnapshots(before, after *Snapshot) *DiffReport {
+	report := &DiffReport{Before: before.Time, After: after.Time}
+
+	beforeRows := make(map[string]*Row)
+	for _, row := range before.rows() {
+		beforeRows[row.Key()] = row
+	}
+
+	var unmatched []*Row
+	for _, row := range after.rows() {
+		prev, ok := beforeRows[row.Key()]
+		if !ok {
+			unmatched = append(unmatched, row)
+			continue
+		}
+		delete(beforeRows, row.Key())
+		if change := diffRows(prev, row); change != nil {
+			report.Changes = append(report.Changes, change)
+		}
+	}
+
+	fallback := make(map[string][]*Row)
+	for _, row := range sortedRows(beforeRows) {
+		if key := fallbackKey(row); key != "" {
+			fallback[key] = append(fallback[key], row)
+		}
+	}
+	for _, row := range unmatched {
+		key := fallbackKey(row)
+		if candidates := fallback[key]; key != "" && len(candidates) > 0 {
+			prev := candidates[0]
+			fallback[key] = candidates[1:]
+			delete(beforeRows, prev.Key())
+
+			change := diffRows(prev, row)
+			if change == nil {
+				change = &EntityChange{Change: ChangeChanged, Kind: row.Kind, ID: row.ID, Name: diffName(row)}
+			}
+			change.BeforeID = prev.ID
+			change.MatchedBy = "target"
+			if row.Target == "" {
+				change.MatchedBy = "address"
+			}
+			report.Changes = append(report.Changes, change)
+			continue
+		}
+
+		report.Changes = append(report.Changes, &EntityChange{
+			Change: ChangeAdded, Kind: row.Kind, ID: row.ID, Name: diffName(row), State: row.State,
+			Started: row.Started, Succeeded: row.Succeeded, Failed: row.Failed,
+		})
+	}
+
+	for _, row := range beforeRows {
+		report.Changes = append(report.Changes, &EntityChange{
+			Change: ChangeRemoved, Kind: row.Kind, ID: row.ID, Name: diffName(row), StateBefore: row.State,
+		})
+	}
+
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if a.Kind != b.Kind {
+			return kindOrder(a.Kind) < kindOrder(b.Kind)
+		}
+		return a.ID < b.ID
+	})
+	return report
+}
+
+// diffRows returns the change between two samples of an entity, or nil when nothing changed.
+func diffRows(prev, cur *Row) *EntityChange {
+	change := &EntityChange{
+		Change:    ChangeChanged,
+		Kind:      cur.Kind,
+		ID:        cur.ID,
+		Name:      diffName(cur),
+		Started:   cur.Started - prev.Started,
+		Succeeded: cur.Succeeded - prev.Succeeded,
+		Failed:    cur.Failed - prev.Failed,
+	}
+	if prev.State != cur.State {
+		change.StateBefore = prev.State
+		change.State = cur.State
+	}
+	if change.State == "" && change.Started == 0 && change.Succeeded == 0 && change.Failed == 0 {
+		return nil
+	}
+	return change
+}
+
+// fallbackKey is the key of the row when its ID did not match, empty when it has none.
+func fallbackKey(row *Row) string {
+	switch {
+	case row.Target != "":
+		return row.Kind + "|" + row.Target
+	case row.Local != "" || row.Remote != "":
+		return row.Kind + "|" + row.Local + "|" + row.Remote
+	}
+	return ""
+}
+
+func diffName(row *Row) string {
+	if row.Kind == KindSocket {
+		return decorateEmpty(row.Local) + " -> " + decorateEmpty(row.Remote)
+	}
+	return row.DisplayName()
+}
+
+func kindOrder(kind string) int {
+	for i, k := range []string{KindChannel, KindSubchannel, KindServer, KindSocket} {
+		if k == kind {
+			return i
+		}
+	}
+	return len(kind)
+}
+
+func sortedRows(rows map[string]*Row) []*Row {
+	sorted := make([]*Row, 0, len(rows))
+	for _, row := range rows {
+		sorted = append(sorted, row)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
+
+// Diff prints the change report between two snapshots.
+func (cc *Client) Diff(opts *Options, before, after *Snapshot) error {
+	report := DiffSnapshots(before, after)
+	if opts.document() {
+		return cc.encode(opts, report)
+	}
+
+	cc.printf("Before: %s\n", report.Before.UTC().String())
+	cc.printf("After:  %s (%s later)\n", report.After.UTC().String(), prettyDuration(report.After.Sub(report.Before)))
+	cc.printf("\n")
+
+	if len(report.Changes) == 0 {
+		cc.printf("No changes\n")
+		return nil
+	}
+
+	type counts struct{ added, removed, changed int }
+	summary := make(map[string]*counts)
+	for _, change := range report.Changes {
+		c := summary[change.Kind]
+		if c == nil {
+			c = &counts{}
+			summary[change.Kind] = c
+		}
+
+		var details []string
+		switch change.Change {
+		case ChangeAdded:
+			c.added++
+			cc.printf("+ %s %d (%s)", change.Kind, change.ID, change.Name)
+			if change.State != "" {
+				details = append(details, "state "+change.State)
+			}
+		case ChangeRemoved:
+			c.removed++
+			cc.printf("- %s %d (%s)", change.Kind, change.ID, change.Name)
+		case ChangeChanged:
+			c.changed++
+			cc.printf("~ %s %d (%s)", change.Kind, change.ID, change.Name)
+			if change.MatchedBy != "" {
+				details = append(details, fmt.Sprintf("was %d, matched by %s", change.BeforeID, change.MatchedBy))
+			}
+			if change.State != "" {
+				details = append(details, fmt.Sprintf("state %s -> %s", change.StateBefore, change.State))
+			}
+			if change.Started != 0 || change.Succeeded != 0 || change.Failed != 0 {
+				details = append(details, fmt.Sprintf("started %+d, succeeded %+d, failed %+d",
+					change.Started, change.Succeeded, change.Failed))
+			}
+		}
+		if len(details) > 0 {
+			cc.printf(": %s", strings.Join(details, ", "))
+		}
+		cc.printf("\n")
+	}
+
+	cc.printf("\n")
+	for _, kind := range []string{KindChannel, KindSubchannel, KindServer, KindSocket} {
+		if c := summary[kind]; c != nil {
+			cc.printf("%s: %d added, %d removed, %d changed\n", kind, c.added, c.removed, c.changed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+func TestDiff(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+
+	s, err := c.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := ReadSnapshot(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := ReadSnapshot(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after.Time = after.Time.Add(5 * time.Minute)
+	after.Channels[0].Data.CallsStarted += 20
+	after.Channels[0].Data.CallsSucceeded += 15
+	after.Channels[0].Data.CallsFailed += 5
+	after.Subchannels[3].Data.State.State = channelzpb.ChannelConnectivityState_TRANSIENT_FAILURE
+	after.Subchannels[4].Ref.SubchannelId = 40
+	after.Sockets = after.Sockets[1:]
+	after.Sockets[0].Ref.SocketId = 100
+	after.Sockets[0].Remote = nil
+
+	expected := `
+Before: 2018-12-01 21:33:20.123456789 +0000 UTC
+After:  2018-12-01 21:38:20.123456789 +0000 UTC (5m later)
+
+~ channel 0 (foo0): started +20, succeeded +15, failed +5
+~ subchannel 3 (bar3): state READY -> TRANSIENT_FAILURE
+~ subchannel 40 (bar4): was 4, matched by target
+- socket 2 (127.0.1.2:9001 -> 111.111.111.111:30000)
+- socket 3 (127.0.1.2:9001 -> 111.111.111.112:30001)
++ socket 100 (127.0.1.2:9001 -> <none>)
+
+channel: 0 added, 0 removed, 1 changed
+subchannel: 0 added, 0 removed, 2 changed
+socket: 1 added, 2 removed, 0 changed
+`
+	if err := c.Diff(&Options{}, before, after); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestTakeSnapshotNested(t *testing.T) {
+	c := newTestNestedClient(&bytes.Buffer{})
+	fake := c.cc.(*fakeChannelzClient)
+
+	// socket 5 is referred to by two subchannels and a server.
+	shared := &channelzpb.Socket{Ref: &channelzpb.SocketRef{SocketId: 5}, Data: &channelzpb.SocketData{}}
+	fake.sockets = append(fake.sockets, shared)
+	for _, subch := range fake.subchannels[1:] {
+		subch.SocketRef = append(subch.SocketRef, shared.Ref)
+	}
+	fake.servers = []*channelzpb.Server{{Ref: &channelzpb.ServerRef{ServerId: 1}, Data: &channelzpb.ServerData{}}}
+	fake.serverSockets = map[int64][]*channelzpb.SocketRef{1: {shared.Ref}}
+
+	s, err := c.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var channels, subchannels, sockets []int64
+	for _, channel := range s.Channels {
+		channels = append(channels, channel.Ref.ChannelId)
+	}
+	for _, subch := range s.Subchannels {
+		subchannels = append(subchannels, subch.Ref.SubchannelId)
+	}
+	for _, socket := range s.Sockets {
+		sockets = append(sockets, socket.Ref.SocketId)
+	}
+	assertOutput(t, "[1 2 3] [20 10 30] [5]", fmt.Sprint(channels, subchannels, sockets))
+}
@@ -0,0 +1,292 @@
+package channelz
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// documentMarshaler encodes the protobuf messages of documents in canonical proto3 JSON:
+// lowerCamelCase field names, timestamps in RFC3339 and durations like "1.5s".
+var documentMarshaler = protojson.MarshalOptions{}
+
+// queryEncoder encodes the documents the queries and the custom columns are evaluated over.
+// Unlike in the JSON and YAML documents, the unset fields of the protobuf messages have their
+// zero value, so that every entity has an ID, and counters, even when they are zero.
+var queryEncoder = documentEncoder{protojson.MarshalOptions{EmitUnpopulated: true}}
+
+var (
+	protoMessageType  = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// marshalDocument encodes v as JSON following the rules of encoding/json, except for the
+// protobuf messages in v, which are encoded with documentMarshaler.
+func marshalDocument(v interface{}) ([]byte, error) {
+	return documentEncoder{documentMarshaler}.marshal(v)
+}
+
+// documentEncoder encodes the documents with the protobuf messages in them encoded by m.
+type documentEncoder struct {
+	m protojson.MarshalOptions
+}
+
+func (e documentEncoder) marshal(v interface{}) ([]byte, error) {
+	d, err := e.value(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(d)
+}
+
+// generic decodes the document of v into maps, slices and json.Numbers.
+func (e documentEncoder) generic(v interface{}) (interface{}, error) {
+	data, err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	return doc, dec.Decode(&doc)
+}
+
+// documentObject is a JSON object which keeps the order its fields were set in.
+type documentObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *documentObject) set(key string, v interface{}) {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+func (o *documentObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// value returns the value json.Marshal encodes as the document of v.
+func (e documentEncoder) value(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if m, ok := protoMessage(v); ok {
+		data, err := e.m.Marshal(m)
+		return json.RawMessage(data), err
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return e.value(v.Elem())
+	case reflect.Struct:
+		o := &documentObject{}
+		if err := e.setFields(o, v); err != nil {
+			return nil, err
+		}
+		return o, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface(), nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := e.value(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, err := e.value(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[iter.Key().String()] = item
+		}
+		return m, nil
+	}
+	return v.Interface(), nil
+}
+
+// protoMessage returns v as a protobuf message, unless v only implements proto.Message
+// through a message it embeds, as SocketView does.
+func protoMessage(v reflect.Value) (proto.Message, bool) {
+	if !v.Type().Implements(protoMessageType) {
+		return nil, false
+	}
+	m := v.Interface().(proto.Message)
+	if reflect.TypeOf(m.ProtoReflect().Interface()) != v.Type() {
+		return nil, false
+	}
+	return m, true
+}
+
+// setFields sets the exported fields of the struct v on o, under the names of their json
+// tags, inlining the embedded structs and messages. The fields of v take precedence over the
+// fields of the embedded ones, as with encoding/json.
+func (e documentEncoder) setFields(o *documentObject, v reflect.Value) error {
+	typ := v.Type()
+	var own []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		fv := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			// an embedded interface is inlined as the struct or message it holds.
+			for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() != reflect.Struct {
+				continue
+			}
+			if err := e.setEmbeddedFields(o, fv, own); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+
+		d, err := e.value(fv)
+		if err != nil {
+			return err
+		}
+		o.set(name, d)
+		own = append(own, name)
+	}
+	return nil
+}
+
+// setEmbeddedFields sets the fields of the embedded struct or message v on o,
+// unless they are already set by the fields named own.
+func (e documentEncoder) setEmbeddedFields(o *documentObject, v reflect.Value, own []string) error {
+	embedded := &documentObject{}
+	var m proto.Message
+	if v.CanAddr() {
+		m, _ = protoMessage(v.Addr())
+	}
+	if m != nil {
+		data, err := e.m.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if err := embedded.unmarshal(data); err != nil {
+			return err
+		}
+	} else if v.Kind() == reflect.Struct {
+		if err := e.setFields(embedded, v); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range embedded.keys {
+		if !containsString(own, key) {
+			o.set(key, embedded.values[key])
+		}
+	}
+	return nil
+}
+
+// unmarshal sets the fields of the JSON object data on o, in order.
+func (o *documentObject) unmarshal(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		o.set(key.(string), raw)
+	}
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty, as the omitempty option of encoding/json defines it.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
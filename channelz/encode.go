@@ -0,0 +1,121 @@
+package channelz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// encode writes v as a JSON document, as YAML when opts.Yaml is set, or with -o ndjson as one
+// line per element when v is a list. The protobuf messages are encoded with protojson: with their
+// protobuf field names and RFC3339 timestamps. With opts.Query, or -o jsonpath, the results of
+// the expression over the JSON view of v are written instead.
+func (cc *Client) encode(opts *Options, v interface{}) error {
+	if opts.Query != "" || strings.HasPrefix(opts.Format, FormatJSONPath+"=") {
+		return cc.evaluate(opts, v)
+	}
+
+	data, err := marshalDocument(v)
+	if err != nil {
+		return err
+	}
+	switch {
+	case opts.Yaml:
+	case opts.Format == FormatNDJSON:
+		return cc.writeLines(data)
+	default:
+		_, err = cc.w.Write(append(data, '\n'))
+		return err
+	}
+
+	// JSON is valid YAML, so decoding it into a node keeps the key order of
+	// the JSON renderer; only the flow styles need to be reset to block ones.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetYAMLStyle(&node)
+
+	enc := yaml.NewEncoder(cc.w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// writeLines writes the elements of the JSON array data one per line, or data when it is not an array.
+func (cc *Client) writeLines(data []byte) error {
+	var items []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+	} else {
+		items = []json.RawMessage{data}
+	}
+
+	for _, item := range items {
+		if _, err := cc.w.Write(append(item, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluate writes the results of opts.Query over the JSON view of v, one JSON value per line,
+// or the -o jsonpath template evaluated over it. The JSON view has the unset fields too.
+func (cc *Client) evaluate(opts *Options, v interface{}) error {
+	_, template, isJSONPath := strings.Cut(opts.Format, FormatJSONPath+"=")
+	if opts.Query != "" && isJSONPath {
+		return fmt.Errorf("--query can not be combined with -o %s", FormatJSONPath)
+	}
+
+	doc, err := queryEncoder.generic(v)
+	if err != nil {
+		return err
+	}
+
+	if isJSONPath {
+		tmpl, err := compileJSONPath(template)
+		if err != nil {
+			return fmt.Errorf("invalid jsonpath: %v", err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, doc, doc); err != nil {
+			return err
+		}
+		if b.Len() > 0 && !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		_, err = b.WriteTo(cc.w)
+		return err
+	}
+
+	q, err := compileQuery(opts.Query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %v", err)
+	}
+	results, err := q(doc)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(cc.w)
+	enc.SetEscapeHTML(false)
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetYAMLStyle(n)
+	}
+}
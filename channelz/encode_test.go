@@ -0,0 +1,184 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLDescribeServer(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.DescribeServer(&Options{Yaml: true}, context.Background(), "server1"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+ref:
+  serverId: "1"
+  name: server1
+data:
+  callsStarted: "110"
+  callsSucceeded: "99"
+  callsFailed: "11"
+  lastCallStartedTimestamp: "2018-12-01T21:33:20.123456789Z"
+listenSocket:
+  - socketId: "1"
+    name: sock1
+`, b.String())
+}
+
+func TestYAMLListServers(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.List(&Options{Yaml: true}, context.Background(), KindServer); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same document as JSON.
+	assertOutput(t, `
+items:
+  - ref:
+      name: server0
+    data:
+      callsStarted: "100"
+      callsSucceeded: "90"
+      callsFailed: "10"
+    listenSocket:
+      - name: sock0
+  - ref:
+      serverId: "1"
+      name: server1
+    data:
+      callsStarted: "110"
+      callsSucceeded: "99"
+      callsFailed: "11"
+      lastCallStartedTimestamp: "2018-12-01T21:33:20.123456789Z"
+    listenSocket:
+      - socketId: "1"
+        name: sock1
+`, b.String())
+}
+
+func TestYAMLDescribeServers(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.DescribeServer(&Options{Yaml: true}, context.Background(), "server*"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the matches are one array, as in JSON.
+	var servers []interface{}
+	if err := yaml.Unmarshal(b.Bytes(), &servers); err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 2 {
+		t.Errorf("expected an array of 2 servers, got:\n%s", b.String())
+	}
+}
+
+func TestYAMLSocketView(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.DescribeServerSocket(&Options{Yaml: true}, context.Background(), "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fields of the socket are inlined, followed by the formatted addresses.
+	out := b.String()
+	for _, want := range []string{"ref:\n  socketId: \"3\"\n", "  tcpipAddress:\n", "local_address: 127.0.1.2:9001\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestYAMLSnapshot(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.Snapshot(&Options{Yaml: true}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	for _, want := range []string{"time: \"2018-12-01T21:33:20.123456789Z\"\n", "    serverId: \"1\"\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	s, err := ReadSnapshot(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Servers) != 2 || s.Servers[1].Ref.ServerId != 1 {
+		t.Errorf("unexpected servers read back: %v", s.Servers)
+	}
+}
+
+func TestJSONListDocument(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.List(&Options{Json: true}, context.Background(), KindServer); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `{"items":[`+
+		`{"ref":{"name":"server0"},"data":{"callsStarted":"100","callsSucceeded":"90","callsFailed":"10"},"listenSocket":[{"name":"sock0"}]},`+
+		`{"ref":{"serverId":"1","name":"server1"},"data":{"callsStarted":"110","callsSucceeded":"99","callsFailed":"11",`+
+		`"lastCallStartedTimestamp":"2018-12-01T21:33:20.123456789Z"},"listenSocket":[{"socketId":"1","name":"sock1"}]}]}`, b.String())
+
+	var doc struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Items) != 2 {
+		t.Errorf("expected 2 items, got %d", len(doc.Items))
+	}
+}
+
+func TestNDJSONList(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.List(&Options{Format: FormatNDJSON}, context.Background(), KindSocket); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("expected 8 sockets, got %d lines:\n%s", len(lines), b.String())
+	}
+	for _, line := range lines {
+		socket := &channelzpb.Socket{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(line), socket); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		if socket.GetRemote().GetTcpipAddress() == nil {
+			t.Errorf("expected a TCP remote address in %s", line)
+		}
+	}
+}
+
+func TestJSONDescribeMatches(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.DescribeChannel(&Options{Json: true, Regex: true}, context.Background(), "foo."); err != nil {
+		t.Fatal(err)
+	}
+
+	var channels []json.RawMessage
+	if err := json.Unmarshal(b.Bytes(), &channels); err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 2 {
+		t.Errorf("expected an array of 2 channels, got %d", len(channels))
+	}
+}
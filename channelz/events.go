@@ -0,0 +1,292 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// EntityRef identifies the channel or subchannel which logged a trace event, or which it refers to.
+type EntityRef struct {
+	Kind string `json:"kind"`
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (r *EntityRef) key() string {
+	return fmt.Sprintf("%s/%d", r.Kind, r.ID)
+}
+
+func (r *EntityRef) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%s %d", r.Kind, r.ID)
+	}
+	return fmt.Sprintf("%s %d (%s)", r.Kind, r.ID, r.Name)
+}
+
+// TraceEvent is a channel trace event with its source entity and child ref resolved.
+type TraceEvent struct {
+	Source *EntityRef                    `json:"source"`
+	Child  *EntityRef                    `json:"child,omitempty"`
+	Event  *channelzpb.ChannelTraceEvent `json:"event"`
+}
+
+// traceWalker collects the trace events of a channel and its descendants,
+// caching the names of the entities it has seen to resolve child refs.
+type traceWalker struct {
+	cc     *Client
+	names  map[string]string
+	events []*TraceEvent
+	// logged holds the NumEventsLogged of every visited entity, keyed by entity.
+	logged map[string]int64
+}
+
+func newTraceWalker(cc *Client) *traceWalker {
+	return &traceWalker{
+		cc:     cc,
+		names:  make(map[string]string),
+		logged: make(map[string]int64),
+	}
+}
+
+func (w *traceWalker) walkChannel(ctx context.Context, channel *channelzpb.Channel) error {
+	source := &EntityRef{Kind: KindChannel, ID: channel.Ref.ChannelId, Name: entityName(channel.Ref.Name, channel.Data)}
+	w.addTrace(ctx, source, channel.Data.GetTrace())
+	return w.walkChildren(ctx, channel.ChannelRef, channel.SubchannelRef)
+}
+
+func (w *traceWalker) walkSubchannel(ctx context.Context, id int64) error {
+	res, err := w.cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: id})
+	if err != nil {
+		return err
+	}
+
+	subch := res.Subchannel
+	source := &EntityRef{Kind: KindSubchannel, ID: subch.Ref.SubchannelId, Name: entityName(subch.Ref.Name, subch.Data)}
+	w.addTrace(ctx, source, subch.Data.GetTrace())
+	return w.walkChildren(ctx, subch.ChannelRef, subch.SubchannelRef)
+}
+
+func (w *traceWalker) walkChildren(ctx context.Context, channels []*channelzpb.ChannelRef, subchannels []*channelzpb.SubchannelRef) error {
+	for _, ref := range channels {
+		res, err := w.cc.cc.GetChannel(ctx, &channelzpb.GetChannelRequest{ChannelId: ref.ChannelId})
+		if err != nil {
+			return err
+		}
+		if err := w.walkChannel(ctx, res.Channel); err != nil {
+			return err
+		}
+	}
+	for _, ref := range subchannels {
+		if err := w.walkSubchannel(ctx, ref.SubchannelId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *traceWalker) addTrace(ctx context.Context, source *EntityRef, trace *channelzpb.ChannelTrace) {
+	w.names[source.key()] = source.Name
+	if trace == nil {
+		return
+	}
+
+	w.logged[source.key()] = trace.NumEventsLogged
+	for _, ev := range trace.Events {
+		w.events = append(w.events, &TraceEvent{
+			Source: source,
+			Child:  w.resolveChild(ctx, ev),
+			Event:  ev,
+		})
+	}
+}
+
+func (w *traceWalker) resolveChild(ctx context.Context, ev *channelzpb.ChannelTraceEvent) *EntityRef {
+	var child *EntityRef
+	switch {
+	case ev.GetChannelRef() != nil:
+		ref := ev.GetChannelRef()
+		child = &EntityRef{Kind: KindChannel, ID: ref.ChannelId, Name: ref.Name}
+	case ev.GetSubchannelRef() != nil:
+		ref := ev.GetSubchannelRef()
+		child = &EntityRef{Kind: KindSubchannel, ID: ref.SubchannelId, Name: ref.Name}
+	default:
+		return nil
+	}
+
+	if child.Name != "" {
+		return child
+	}
+
+	name, ok := w.names[child.key()]
+	if !ok {
+		// the child may be gone already, so a failed lookup leaves it unnamed.
+		if child.Kind == KindChannel {
+			if res, err := w.cc.cc.GetChannel(ctx, &channelzpb.GetChannelRequest{ChannelId: child.ID}); err == nil {
+				name = entityName(res.Channel.Ref.Name, res.Channel.Data)
+			}
+		} else if res, err := w.cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: child.ID}); err == nil {
+			name = entityName(res.Subchannel.Ref.Name, res.Subchannel.Data)
+		}
+		w.names[child.key()] = name
+	}
+	child.Name = name
+	return child
+}
+
+// sortedEvents returns the collected events in chronological order.
+func (w *traceWalker) sortedEvents() []*TraceEvent {
+	sort.SliceStable(w.events, func(i, j int) bool {
+		return w.events[i].Event.Timestamp.AsTime().Before(w.events[j].Event.Timestamp.AsTime())
+	})
+	return w.events
+}
+
+// entityName returns the ref name of a channel or subchannel, falling back to its target.
+func entityName(name string, data *channelzpb.ChannelData) string {
+	if name != "" {
+		return name
+	}
+	return data.GetTarget()
+}
+
+// ListEvents prints the merged trace event timeline of the top channels matching name,
+// or of all top channels when name is empty.
+func (cc *Client) ListEvents(opts *Options, ctx context.Context, name string) error {
+	if opts.Follow {
+		return cc.followEvents(opts, ctx, name)
+	}
+
+	w, found, err := cc.collectEvents(opts, ctx, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		cc.printf("channel %q not found", name)
+		return nil
+	}
+
+	events := w.sortedEvents()
+	if opts.document() {
+		return cc.encode(opts, events)
+	}
+
+	cc.printEventsHeader()
+	colors := opts.palette()
+	for _, ev := range events {
+		cc.printTraceEvent(colors, ev)
+	}
+	return nil
+}
+
+// collectEvents walks the top channels matching name, or all top channels when name is empty.
+func (cc *Client) collectEvents(opts *Options, ctx context.Context, name string) (w *traceWalker, found bool, err error) {
+	w = newTraceWalker(cc)
+	if name == "" {
+		err := cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
+			return w.walkChannel(ctx, channel)
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		return w, true, nil
+	}
+
+	channels, _, err := cc.findTopChannels(opts, ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, channel := range channels {
+		if err := w.walkChannel(ctx, channel); err != nil {
+			return nil, false, err
+		}
+	}
+	return w, len(channels) > 0, nil
+}
+
+// followEvents polls the trace events every opts.Interval and prints the ones not seen before,
+// until ctx is done. Entities which show up after the start, like new subchannels, are followed too.
+func (cc *Client) followEvents(opts *Options, ctx context.Context, name string) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	seen := make(map[string]bool)
+	var logged map[string]int64
+
+	if !opts.document() {
+		cc.printEventsHeader()
+	}
+
+	for ctx.Err() == nil {
+		w, _, err := cc.collectEvents(opts, ctx, name)
+		if ctx.Err() != nil {
+			// interrupted in the middle of a poll.
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		fresh := make(map[string]int64)
+		for _, ev := range w.sortedEvents() {
+			key := fmt.Sprintf("%s@%d.%09d/%s", ev.Source.key(),
+				ev.Event.GetTimestamp().GetSeconds(), ev.Event.GetTimestamp().GetNanos(), ev.Event.Description)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			fresh[ev.Source.key()]++
+
+			if err := cc.printFollowedEvent(opts, ev); err != nil {
+				return err
+			}
+		}
+
+		// the server keeps a bounded ring buffer of events per entity, so events
+		// logged between two polls which are not in the buffer any more are lost.
+		for key, n := range w.logged {
+			if last, ok := logged[key]; ok && n-last > fresh[key] {
+				opts.warnf("%d trace events of %s (%s) were dropped from the server's buffer", n-last-fresh[key], key, w.names[key])
+			}
+		}
+		logged = w.logged
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+		}
+	}
+	return nil
+}
+
+func (cc *Client) printFollowedEvent(opts *Options, ev *TraceEvent) error {
+	switch {
+	case opts.Yaml:
+		cc.printf("---\n")
+		return cc.encode(opts, ev)
+	case opts.document():
+		return cc.encode(opts, ev)
+	default:
+		cc.printTraceEvent(opts.palette(), ev)
+		return nil
+	}
+}
+
+func (cc *Client) printEventsHeader() {
+	cc.printf("%s\t%s\t%s\t%-80s\t%s\n", "Timestamp", "Severity", "Source", "Description", "Child")
+}
+
+func (cc *Client) printTraceEvent(p palette, ev *TraceEvent) {
+	child := ""
+	if ev.Child != nil {
+		child = ev.Child.String()
+	}
+	cc.printf("%s\t%s\t%s\t%-80s\t%s\n",
+		stringTimestamp(ev.Event.Timestamp), p.severity(prettyChannelTraceEventSeverity(ev.Event.Severity)),
+		ev.Source, ev.Event.Description, decorateEmpty(child))
+}
@@ -0,0 +1,170 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newTraceEvent(offset time.Duration, severity channelzpb.ChannelTraceEvent_Severity, desc string) *channelzpb.ChannelTraceEvent {
+	return &channelzpb.ChannelTraceEvent{
+		Description: desc,
+		Severity:    severity,
+		Timestamp:   timestamppb.New(fixedTime.Add(offset)),
+	}
+}
+
+func newTestEventsClient(b *bytes.Buffer) *Client {
+	created := newTraceEvent(-3*time.Second, channelzpb.ChannelTraceEvent_CT_INFO, "Created subchannel")
+	created.ChildRef = &channelzpb.ChannelTraceEvent_SubchannelRef{
+		SubchannelRef: &channelzpb.SubchannelRef{SubchannelId: 200},
+	}
+
+	subch := &channelzpb.Subchannel{
+		Ref: &channelzpb.SubchannelRef{SubchannelId: 200},
+		Data: &channelzpb.ChannelData{
+			State:  &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_TRANSIENT_FAILURE},
+			Target: "10.0.0.1:443",
+			Trace: &channelzpb.ChannelTrace{
+				NumEventsLogged: 2,
+				Events: []*channelzpb.ChannelTraceEvent{
+					newTraceEvent(-2*time.Second, channelzpb.ChannelTraceEvent_CT_INFO, "Subchannel created"),
+					newTraceEvent(-1*time.Second, channelzpb.ChannelTraceEvent_CT_WARNING, "Subchannel Connectivity change to TRANSIENT_FAILURE"),
+				},
+			},
+		},
+	}
+	channel := &channelzpb.Channel{
+		Ref: &channelzpb.ChannelRef{ChannelId: 100},
+		Data: &channelzpb.ChannelData{
+			State:  &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_CONNECTING},
+			Target: "lb.test.com",
+			Trace: &channelzpb.ChannelTrace{
+				NumEventsLogged: 2,
+				Events: []*channelzpb.ChannelTraceEvent{
+					newTraceEvent(-4*time.Second, channelzpb.ChannelTraceEvent_CT_INFO, "Channel created"),
+					created,
+				},
+			},
+		},
+		SubchannelRef: []*channelzpb.SubchannelRef{subch.Ref},
+	}
+
+	return &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			topChannels: []*channelzpb.Channel{channel},
+			channels:    []*channelzpb.Channel{channel},
+			subchannels: []*channelzpb.Subchannel{subch},
+		},
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	b := &bytes.Buffer{}
+	ctx := context.Background()
+	c := newTestEventsClient(b)
+
+	expected := `
+Timestamp	Severity	Source	Description                                                                     	Child
+2018-12-01 21:33:16.123456789 +0000 UTC	INFO	channel 100 (lb.test.com)	Channel created                                                                 	<none>
+2018-12-01 21:33:17.123456789 +0000 UTC	INFO	channel 100 (lb.test.com)	Created subchannel                                                              	subchannel 200 (10.0.0.1:443)
+2018-12-01 21:33:18.123456789 +0000 UTC	INFO	subchannel 200 (10.0.0.1:443)	Subchannel created                                                              	<none>
+2018-12-01 21:33:19.123456789 +0000 UTC	WARNING	subchannel 200 (10.0.0.1:443)	Subchannel Connectivity change to TRANSIENT_FAILURE                             	<none>
+`
+	t.Run("All", func(t *testing.T) {
+		b.Reset()
+		_ = c.ListEvents(&Options{}, ctx, "")
+		assertOutput(t, expected, b.String())
+	})
+	t.Run("ByID", func(t *testing.T) {
+		b.Reset()
+		_ = c.ListEvents(&Options{}, ctx, "100")
+		assertOutput(t, expected, b.String())
+	})
+}
+
+// hookChannelzClient calls hook before every GetTopChannels, to change the fake between polls.
+type hookChannelzClient struct {
+	*fakeChannelzClient
+	hook func(n int)
+	n    int
+}
+
+func (c *hookChannelzClient) GetTopChannels(ctx context.Context, in *channelzpb.GetTopChannelsRequest, opts ...grpc.CallOption) (*channelzpb.GetTopChannelsResponse, error) {
+	c.n++
+	c.hook(c.n)
+	return c.fakeChannelzClient.GetTopChannels(ctx, in, opts...)
+}
+
+func TestFollowEvents(t *testing.T) {
+	b := &bytes.Buffer{}
+	errb := &bytes.Buffer{}
+	c := newTestEventsClient(b)
+	fake := c.cc.(*fakeChannelzClient)
+	subch := fake.subchannels[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.cc = &hookChannelzClient{
+		fakeChannelzClient: fake,
+		hook: func(n int) {
+			switch n {
+			case 2:
+				// one new event is visible, two more were logged and dropped.
+				subch.Data.Trace.NumEventsLogged += 3
+				subch.Data.Trace.Events = append(subch.Data.Trace.Events[1:],
+					newTraceEvent(0, channelzpb.ChannelTraceEvent_CT_INFO, "Subchannel Connectivity change to READY"))
+			case 3:
+				cancel()
+			}
+		},
+	}
+
+	expected := `
+Timestamp	Severity	Source	Description                                                                     	Child
+2018-12-01 21:33:16.123456789 +0000 UTC	INFO	channel 100 (lb.test.com)	Channel created                                                                 	<none>
+2018-12-01 21:33:17.123456789 +0000 UTC	INFO	channel 100 (lb.test.com)	Created subchannel                                                              	subchannel 200 (10.0.0.1:443)
+2018-12-01 21:33:18.123456789 +0000 UTC	INFO	subchannel 200 (10.0.0.1:443)	Subchannel created                                                              	<none>
+2018-12-01 21:33:19.123456789 +0000 UTC	WARNING	subchannel 200 (10.0.0.1:443)	Subchannel Connectivity change to TRANSIENT_FAILURE                             	<none>
+2018-12-01 21:33:20.123456789 +0000 UTC	INFO	subchannel 200 (10.0.0.1:443)	Subchannel Connectivity change to READY                                         	<none>
+`
+	opts := &Options{Follow: true, Interval: time.Millisecond, ErrOutput: errb}
+	if err := c.ListEvents(opts, ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+	assertOutput(t, "warning: 2 trace events of subchannel/200 (10.0.0.1:443) were dropped from the server's buffer", errb.String())
+}
+
+// cancelingChannelzClient cancels the context of the command in the middle of a poll,
+// as an interrupt does.
+type cancelingChannelzClient struct {
+	*fakeChannelzClient
+	cancel func()
+}
+
+func (c *cancelingChannelzClient) GetSubchannel(context.Context, *channelzpb.GetSubchannelRequest, ...grpc.CallOption) (*channelzpb.GetSubchannelResponse, error) {
+	c.cancel()
+	return nil, status.Error(codes.Canceled, context.Canceled.Error())
+}
+
+func TestFollowEventsInterrupted(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestEventsClient(b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.cc = &cancelingChannelzClient{fakeChannelzClient: c.cc.(*fakeChannelzClient), cancel: cancel}
+
+	if err := c.ListEvents(&Options{Follow: true, Interval: time.Millisecond}, ctx, ""); err != nil {
+		t.Errorf("expected a quiet return, got %v", err)
+	}
+}
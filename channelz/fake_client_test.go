@@ -17,23 +17,42 @@ import (
 var _ channelzpb.ChannelzClient = (*fakeChannelzClient)(nil)
 
 type fakeChannelzClient struct {
-	topChannels []*channelzpb.Channel
-	servers     []*channelzpb.Server
-	channels    []*channelzpb.Channel
-	subchannels []*channelzpb.Subchannel
-	sockets     []*channelzpb.Socket
+	topChannels   []*channelzpb.Channel
+	servers       []*channelzpb.Server
+	channels      []*channelzpb.Channel
+	subchannels   []*channelzpb.Subchannel
+	sockets       []*channelzpb.Socket
+	serverSockets map[int64][]*channelzpb.SocketRef
+	// serverPage, when set, is the number of servers GetServers returns per page.
+	serverPage int
+	// topChannelsErr, when set, is the error of GetTopChannels, which counts its calls in topChannelsCalls.
+	topChannelsErr   error
+	topChannelsCalls int
 }
 
 func (c *fakeChannelzClient) GetTopChannels(context.Context, *channelzpb.GetTopChannelsRequest, ...grpc.CallOption) (*channelzpb.GetTopChannelsResponse, error) {
+	c.topChannelsCalls++
+	if c.topChannelsErr != nil {
+		return nil, c.topChannelsErr
+	}
 	return &channelzpb.GetTopChannelsResponse{
 		Channel: c.topChannels,
 		End:     true,
 	}, nil
 }
 
-func (c *fakeChannelzClient) GetServers(context.Context, *channelzpb.GetServersRequest, ...grpc.CallOption) (*channelzpb.GetServersResponse, error) {
+func (c *fakeChannelzClient) GetServers(_ context.Context, in *channelzpb.GetServersRequest, _ ...grpc.CallOption) (*channelzpb.GetServersResponse, error) {
+	var servers []*channelzpb.Server
+	for _, s := range c.servers {
+		if s.Ref.ServerId >= in.StartServerId {
+			servers = append(servers, s)
+		}
+	}
+	if c.serverPage > 0 && len(servers) > c.serverPage {
+		return &channelzpb.GetServersResponse{Server: servers[:c.serverPage]}, nil
+	}
 	return &channelzpb.GetServersResponse{
-		Server: c.servers,
+		Server: servers,
 		End:    true,
 	}, nil
 }
@@ -49,8 +68,17 @@ func (c *fakeChannelzClient) GetServer(_ context.Context, in *channelzpb.GetServ
 	return nil, status.Errorf(codes.NotFound, "not found")
 }
 
-func (c *fakeChannelzClient) GetServerSockets(context.Context, *channelzpb.GetServerSocketsRequest, ...grpc.CallOption) (*channelzpb.GetServerSocketsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "not implemented")
+func (c *fakeChannelzClient) GetServerSockets(_ context.Context, in *channelzpb.GetServerSocketsRequest, _ ...grpc.CallOption) (*channelzpb.GetServerSocketsResponse, error) {
+	var refs []*channelzpb.SocketRef
+	for _, ref := range c.serverSockets[in.ServerId] {
+		if ref.SocketId >= in.StartSocketId {
+			refs = append(refs, ref)
+		}
+	}
+	return &channelzpb.GetServerSocketsResponse{
+		SocketRef: refs,
+		End:       true,
+	}, nil
 }
 
 func (c *fakeChannelzClient) GetChannel(_ context.Context, in *channelzpb.GetChannelRequest, _ ...grpc.CallOption) (*channelzpb.GetChannelResponse, error) {
@@ -288,6 +316,23 @@ func init() {
 		subchRef:                 subchRef1,
 	})
 
+	var srvSocks2 []*channelzpb.Socket
+	var srvSockRef2 []*channelzpb.SocketRef
+	for i := 0; i < 3; i++ {
+		socket := testCreateSocket(socketParam{
+			localIP:    net.IPv4(127, 0, 1, 2),
+			localPort:  9001,
+			remoteIP:   net.IPv4(10, 0, 0, byte(1+i)),
+			remotePort: int32(40000 + i),
+		})
+		socket.Data.StreamsStarted = int64(10 * (i + 1))
+		socket.Data.StreamsSucceeded = int64(9 * (i + 1))
+		socket.Data.StreamsFailed = int64(i + 1)
+		socket.Data.LastMessageSentTimestamp = ts1
+		srvSocks2 = append(srvSocks2, socket)
+		srvSockRef2 = append(srvSockRef2, socket.Ref)
+	}
+
 	fakeChannelzClient1 = &fakeChannelzClient{
 		topChannels: []*channelzpb.Channel{topch1, topch2},
 		channels: []*channelzpb.Channel{
@@ -299,7 +344,10 @@ func init() {
 		sockets: append([]*channelzpb.Socket{
 			srvsock1, srvsock2,
 			subchsock1,
-		}, subchSocks1...),
+		}, append(subchSocks1, srvSocks2...)...),
 		servers: []*channelzpb.Server{srv1, srv2},
+		serverSockets: map[int64][]*channelzpb.SocketRef{
+			srv2.Ref.ServerId: srvSockRef2,
+		},
 	}
 }
@@ -0,0 +1,177 @@
+package channelz
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RowFilter is the set of filters of the list command, applied to the rows of every kind.
+// Zero fields do not filter.
+type RowFilter struct {
+	// State is a comma separated list of connectivity states, case insensitive.
+	State string
+	// Target and Name are regular expressions.
+	Target string
+	Name   string
+	// MinFailed keeps the rows with at least this many failed calls or streams.
+	MinFailed int64
+	// FailureRatio compares the failed share of the finished calls, as in ">0.05", "<=1%".
+	FailureRatio string
+	// IdleFor keeps the rows without a call or socket activity for this long.
+	IdleFor time.Duration
+	// Remote is a CIDR, or a single IP, the remote address must be in.
+	Remote string
+}
+
+// rowMatcher is a compiled RowFilter.
+type rowMatcher struct {
+	states  map[string]bool
+	target  *regexp.Regexp
+	name    *regexp.Regexp
+	filter  RowFilter
+	ratio   func(float64) bool
+	remote  *net.IPNet
+	enabled bool
+}
+
+func (f RowFilter) compile() (*rowMatcher, error) {
+	m := &rowMatcher{filter: f, enabled: f != RowFilter{}}
+
+	if f.State != "" {
+		m.states = make(map[string]bool)
+		for _, s := range strings.Split(f.State, ",") {
+			m.states[strings.ToUpper(strings.TrimSpace(s))] = true
+		}
+	}
+
+	var err error
+	if f.Target != "" {
+		if m.target, err = regexp.Compile(f.Target); err != nil {
+			return nil, fmt.Errorf("invalid target regex: %v", err)
+		}
+	}
+	if f.Name != "" {
+		if m.name, err = regexp.Compile(f.Name); err != nil {
+			return nil, fmt.Errorf("invalid name regex: %v", err)
+		}
+	}
+	if f.FailureRatio != "" {
+		if m.ratio, err = parseComparison(f.FailureRatio); err != nil {
+			return nil, fmt.Errorf("invalid failure ratio: %v", err)
+		}
+	}
+	if f.Remote != "" {
+		if m.remote, err = parseCIDR(f.Remote); err != nil {
+			return nil, fmt.Errorf("invalid remote: %v", err)
+		}
+	}
+	return m, nil
+}
+
+// parseComparison parses a comparison like ">0.05", "<=5%" or "0.1", which means ">=0.1".
+func parseComparison(s string) (func(float64) bool, error) {
+	op := ">="
+	for _, o := range []string{">=", "<=", "==", ">", "<", "="} {
+		if strings.HasPrefix(s, o) {
+			op, s = o, s[len(o):]
+			break
+		}
+	}
+
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s, scale = strings.TrimSuffix(s, "%"), 0.01
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil, err
+	}
+	v *= scale
+
+	switch op {
+	case ">":
+		return func(x float64) bool { return x > v }, nil
+	case "<":
+		return func(x float64) bool { return x < v }, nil
+	case "<=":
+		return func(x float64) bool { return x <= v }, nil
+	case "=", "==":
+		return func(x float64) bool { return x == v }, nil
+	}
+	return func(x float64) bool { return x >= v }, nil
+}
+
+func parseCIDR(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, ipNet, err := net.ParseCIDR(s)
+		return ipNet, err
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is neither a CIDR nor an IP", s)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// failureRatio is the failed share of the finished calls of the row, 0 without finished calls.
+func failureRatio(row *Row) float64 {
+	finished := row.Succeeded + row.Failed
+	if finished == 0 {
+		return 0
+	}
+	return float64(row.Failed) / float64(finished)
+}
+
+// remoteIP returns the IP of the remote TCP address of the row, nil for other addresses.
+func remoteIP(row *Row) net.IP {
+	host, _, err := net.SplitHostPort(row.Remote)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// Match reports whether the row passes every filter, at now.
+func (m *rowMatcher) Match(now time.Time, row *Row) bool {
+	if !m.enabled {
+		return true
+	}
+
+	if m.states != nil && !m.states[row.State] {
+		return false
+	}
+	if m.target != nil && !m.target.MatchString(row.Target) {
+		return false
+	}
+	if m.name != nil && !m.name.MatchString(row.Name) {
+		return false
+	}
+	if row.Failed < m.filter.MinFailed {
+		return false
+	}
+	if m.ratio != nil && !m.ratio(failureRatio(row)) {
+		return false
+	}
+	if m.filter.IdleFor > 0 {
+		last := row.LastCall
+		if last != nil && (last.Seconds != 0 || last.Nanos != 0) && now.Sub(last.AsTime()) < m.filter.IdleFor {
+			return false
+		}
+	}
+	if m.remote != nil {
+		ip := remoteIP(row)
+		if ip == nil || !m.remote.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
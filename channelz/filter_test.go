@@ -0,0 +1,85 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestListFilters(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		kind     string
+		filter   RowFilter
+		expected string
+	}{
+		{
+			name:   "name and min failed",
+			kind:   KindSubchannel,
+			filter: RowFilter{Name: `^bar[1-4]$`, MinFailed: 13},
+			expected: `
+ID  Channel  Name  State  Socket  Calls  Success  Fail  LastCall
+3   1        bar3  READY  1       130    117      13    0ms
+4   1        bar4  READY  1       140    126      14    0ms
+`,
+		},
+		{
+			name:   "state",
+			kind:   KindChannel,
+			filter: RowFilter{State: "transient_failure, connecting"},
+			expected: `
+ID  Name  State  Channel  SubChannel  Calls  Success  Fail  LastCall
+`,
+		},
+		{
+			name:   "target and failure ratio",
+			kind:   KindChannel,
+			filter: RowFilter{Target: `^foo1\.`, FailureRatio: ">9%"},
+			expected: `
+ID  Name  State  Channel  SubChannel  Calls  Success  Fail  LastCall
+1   foo1  READY  0        4           110    99       11    0ms
+`,
+		},
+		{
+			name:   "remote",
+			kind:   KindSocket,
+			filter: RowFilter{Remote: "10.0.0.0/31"},
+			expected: `
+ID  Owner     Local           Remote          Started  Success  Fail  Msgs  LastActivity
+7   server/1  127.0.1.2:9001  10.0.0.1:40000  10       9        1     0     0ms
+`,
+		},
+		{
+			name:   "idle",
+			kind:   KindServer,
+			filter: RowFilter{IdleFor: time.Minute},
+			expected: `
+ID  Name     LocalAddr       Calls  Success  Fail  LastCall
+0   server0  127.0.1.2:9000  100    90       10    none
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			c := newTestClient1(b)
+			if err := c.List(&Options{Filter: tc.filter}, context.Background(), tc.kind); err != nil {
+				t.Fatal(err)
+			}
+			assertOutput(t, tc.expected, b.String())
+		})
+	}
+}
+
+func TestListFilterErrors(t *testing.T) {
+	for _, filter := range []RowFilter{
+		{Name: "("},
+		{FailureRatio: ">x"},
+		{Remote: "10.0.0.0/33"},
+	} {
+		c := newTestClient1(&bytes.Buffer{})
+		if err := c.List(&Options{Filter: filter}, context.Background(), KindChannel); err == nil {
+			t.Errorf("expected an error for %+v", filter)
+		}
+	}
+}
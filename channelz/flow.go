@@ -0,0 +1,142 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Defaults of the flow stall symptoms.
+const (
+	DefaultWindowBelow = 16 * 1024
+	DefaultIdle        = 30 * time.Second
+)
+
+// FlowRow is the HTTP/2 flow-control state of a socket, with the symptoms of a stall it shows.
+type FlowRow struct {
+	Socket *EntityRef `json:"socket"`
+	Owner  *EntityRef `json:"owner,omitempty"`
+	Local  string     `json:"local"`
+	Remote string     `json:"remote"`
+	// LocalWindow and RemoteWindow are nil when the transport does not report them.
+	LocalWindow  *int64 `json:"local_window,omitempty"`
+	RemoteWindow *int64 `json:"remote_window,omitempty"`
+	// Active is the number of streams started and not finished yet.
+	Active      int64                `json:"active_streams"`
+	LastMessage *timestamp.Timestamp `json:"last_message,omitempty"`
+	Symptoms    []string             `json:"symptoms"`
+}
+
+func flowWindow(v *wrapperspb.Int64Value) *int64 {
+	if v == nil {
+		return nil
+	}
+	w := v.Value
+	return &w
+}
+
+// flowRow returns the flow-control state of the socket at now. A window below windowBelow,
+// and active streams without a message for idle, are reported as symptoms.
+func flowRow(now time.Time, socket *channelzpb.Socket, owner *EntityRef, windowBelow int64, idle time.Duration) *FlowRow {
+	data := socket.GetData()
+	row := &FlowRow{
+		Socket:       &EntityRef{Kind: KindSocket, ID: socket.Ref.SocketId, Name: socket.Ref.Name},
+		Owner:        owner,
+		Local:        addrToString(socket.Local),
+		Remote:       addrToString(socket.Remote),
+		LocalWindow:  flowWindow(data.GetLocalFlowControlWindow()),
+		RemoteWindow: flowWindow(data.GetRemoteFlowControlWindow()),
+		Active:       data.GetStreamsStarted() - data.GetStreamsSucceeded() - data.GetStreamsFailed(),
+		LastMessage:  latestTimestamp(data.GetLastMessageSentTimestamp(), data.GetLastMessageReceivedTimestamp()),
+	}
+
+	for _, w := range []struct {
+		side   string
+		window *int64
+	}{{"local", row.LocalWindow}, {"remote", row.RemoteWindow}} {
+		switch {
+		case w.window == nil:
+		case *w.window <= 0:
+			row.Symptoms = append(row.Symptoms, fmt.Sprintf("zero %s window", w.side))
+		case *w.window < windowBelow:
+			row.Symptoms = append(row.Symptoms, fmt.Sprintf("small %s window", w.side))
+		}
+	}
+
+	if row.Active > 0 {
+		switch {
+		case row.LastMessage == nil:
+			row.Symptoms = append(row.Symptoms, fmt.Sprintf("%d active streams, no message", row.Active))
+		case now.Sub(row.LastMessage.AsTime()) >= idle:
+			row.Symptoms = append(row.Symptoms, fmt.Sprintf("%d active streams, idle %s",
+				row.Active, prettyDuration(now.Sub(row.LastMessage.AsTime()))))
+		}
+	}
+	return row
+}
+
+func formatWindow(w *int64) string {
+	if w == nil {
+		return "-"
+	}
+	return fmt.Sprint(*w)
+}
+
+// FlowSockets prints the sockets showing symptoms of HTTP/2 flow-control stalls: small or zero
+// windows, and active streams without recent messages. The sockets with the most symptoms and
+// active streams come first. With opts.All every socket is printed.
+func (cc *Client) FlowSockets(opts *Options, ctx context.Context) error {
+	windowBelow := opts.WindowBelow
+	if windowBelow == 0 {
+		windowBelow = DefaultWindowBelow
+	}
+	idle := opts.Idle
+	if idle == 0 {
+		idle = DefaultIdle
+	}
+
+	now := timeNow()
+	var rows []*FlowRow
+	err := cc.visitSockets(ctx, func(socket *channelzpb.Socket, owner *EntityRef) error {
+		row := flowRow(now, socket, owner, windowBelow, idle)
+		if opts.All || len(row.Symptoms) > 0 {
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if len(rows[i].Symptoms) != len(rows[j].Symptoms) {
+			return len(rows[i].Symptoms) > len(rows[j].Symptoms)
+		}
+		return rows[i].Active > rows[j].Active
+	})
+
+	if opts.document() {
+		if rows == nil {
+			rows = []*FlowRow{}
+		}
+		return cc.encode(opts, rows)
+	}
+
+	cc.printf("ID\tOwner\tRemote\tLocalWindow\tRemoteWindow\tActive\tLastMessage\tSymptoms\n")
+	for _, row := range rows {
+		owner := "-"
+		if row.Owner != nil {
+			owner = row.Owner.String()
+		}
+		cc.printf("%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n", row.Socket.ID, owner, decorateEmpty(row.Remote),
+			formatWindow(row.LocalWindow), formatWindow(row.RemoteWindow), row.Active,
+			elapsedTimestamp(now, row.LastMessage), decorateEmpty(strings.Join(row.Symptoms, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newFlowSocket(id int64, remote byte, local, peer int64, started, finished int64, lastMessage time.Duration) *channelzpb.Socket {
+	socket := testCreateSocket(socketParam{
+		localIP:    net.IPv4(127, 0, 0, 1),
+		localPort:  9001,
+		remoteIP:   net.IPv4(10, 0, 0, remote),
+		remotePort: 40000,
+	})
+	socket.Ref.SocketId = id
+	socket.Data.LocalFlowControlWindow = wrapperspb.Int64(local)
+	socket.Data.RemoteFlowControlWindow = wrapperspb.Int64(peer)
+	socket.Data.StreamsStarted = started
+	socket.Data.StreamsSucceeded = finished
+	socket.Data.LastMessageSentTimestamp = timestamppb.New(fixedTime.Add(-lastMessage))
+	return socket
+}
+
+func TestFlowSockets(t *testing.T) {
+	healthy := newFlowSocket(21, 1, 65535, 65535, 10, 10, 2*time.Second)
+	stalled := newFlowSocket(22, 2, 65535, 0, 12, 4, 5*time.Minute)
+	busy := newFlowSocket(23, 3, 1024, 65535, 3, 1, 2*time.Second)
+
+	server := &channelzpb.Server{Ref: &channelzpb.ServerRef{ServerId: 1, Name: "srv"}, Data: &channelzpb.ServerData{}}
+	b := &bytes.Buffer{}
+	c := &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			servers:       []*channelzpb.Server{server},
+			sockets:       []*channelzpb.Socket{healthy, stalled, busy},
+			serverSockets: map[int64][]*channelzpb.SocketRef{1: {healthy.Ref, stalled.Ref, busy.Ref}},
+		},
+	}
+
+	expected := `
+ID	Owner	Remote	LocalWindow	RemoteWindow	Active	LastMessage	Symptoms
+22	server 1 (srv)	10.0.0.2:40000	65535	0	8	5m	zero remote window, 8 active streams, idle 5m
+23	server 1 (srv)	10.0.0.3:40000	1024	65535	2	2s	small local window
+`
+	if err := c.FlowSockets(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+
+	b.Reset()
+	expected = `
+ID	Owner	Remote	LocalWindow	RemoteWindow	Active	LastMessage	Symptoms
+22	server 1 (srv)	10.0.0.2:40000	65535	0	8	5m	zero remote window, 8 active streams, idle 5m
+23	server 1 (srv)	10.0.0.3:40000	1024	65535	2	2s	small local window
+21	server 1 (srv)	10.0.0.1:40000	65535	65535	0	2s	<none>
+`
+	if err := c.FlowSockets(&Options{All: true}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
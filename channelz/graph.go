@@ -0,0 +1,359 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// Output formats of graph.
+const (
+	// FormatDot is a Graphviz digraph.
+	FormatDot = "dot"
+	// FormatMermaid is a Mermaid flowchart, which Markdown renderers draw.
+	FormatMermaid = "mermaid"
+)
+
+// Kinds of the nodes of the topology graph.
+const (
+	graphChannel    = "channel"
+	graphSubchannel = "subchannel"
+	graphServer     = "server"
+	graphListen     = "listen"
+	graphSocket     = "socket"
+	graphAddress    = "address"
+)
+
+// graphNode is an entity of the topology graph.
+type graphNode struct {
+	id    string
+	kind  string
+	state string
+	// label holds the lines of the label of the node.
+	label []string
+}
+
+// graphEdge links a node to one of its children, labelled with the calls or streams of the child.
+type graphEdge struct {
+	from, to string
+	label    string
+}
+
+// topology is the graph of the top channels down to the remote addresses of their sockets,
+// and of the servers down to the sockets accepted on their listen sockets.
+type topology struct {
+	nodes []*graphNode
+	index map[string]*graphNode
+	edges []graphEdge
+	// collapse draws the subchannels of a channel to the same target as one node.
+	collapse bool
+}
+
+func (cc *Client) Graph(opts *Options, ctx context.Context) error {
+	var write func(*topology, io.Writer) error
+	switch opts.Format {
+	case "", FormatDot:
+		write = (*topology).writeDot
+	case FormatMermaid:
+		write = (*topology).writeMermaid
+	}
+	if write == nil || opts.document() {
+		return fmt.Errorf("graph renders -o %s or -o %s", FormatDot, FormatMermaid)
+	}
+
+	t := &topology{collapse: opts.Collapse}
+	err := cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
+		node, err := cc.channelTree(ctx, channel)
+		if err != nil {
+			return err
+		}
+		t.root(t.addChannel(node), callsLabel(node.Data.CallsStarted, node.Data.CallsFailed))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
+		node, err := cc.serverTree(ctx, server)
+		if err != nil {
+			return err
+		}
+		t.root(t.addServer(node), callsLabel(node.Data.CallsStarted, node.Data.CallsFailed))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return write(t, cc.w)
+}
+
+// node adds the node id, unless it is already in the graph, and returns id.
+func (t *topology) node(id, kind, state string, label ...string) string {
+	if t.index == nil {
+		t.index = make(map[string]*graphNode)
+	}
+	if _, ok := t.index[id]; !ok {
+		n := &graphNode{id: id, kind: kind, state: state, label: label}
+		t.nodes = append(t.nodes, n)
+		t.index[id] = n
+	}
+	return id
+}
+
+func (t *topology) edge(from, to, label string) {
+	t.edges = append(t.edges, graphEdge{from: from, to: to, label: label})
+}
+
+// root adds the calls of a root node, which no edge is labelled with, to its label.
+func (t *topology) root(id, calls string) {
+	n := t.index[id]
+	n.label = append(n.label, calls)
+}
+
+func (t *topology) addChannel(node *ChannelNode) string {
+	id := t.node(fmt.Sprintf("channel%d", node.Ref.ChannelId), graphChannel,
+		node.Data.GetState().GetState().String(),
+		fmt.Sprintf("channel %d", node.Ref.ChannelId), node.Data.Target)
+	t.addChildren(id, node.Channels, node.Subchannels, node.Sockets)
+	return id
+}
+
+func (t *topology) addSubchannel(node *SubchannelNode) string {
+	id := t.node(fmt.Sprintf("subchannel%d", node.Ref.SubchannelId), graphSubchannel,
+		node.Data.GetState().GetState().String(),
+		fmt.Sprintf("subchannel %d", node.Ref.SubchannelId), node.Data.Target)
+	t.addChildren(id, node.Channels, node.Subchannels, node.Sockets)
+	return id
+}
+
+// addChildren adds the nested channels, subchannels and sockets of the channel or subchannel id.
+func (t *topology) addChildren(id string, channels []*ChannelNode, subchannels []*SubchannelNode, sockets []*SocketView) {
+	for _, ch := range channels {
+		t.edge(id, t.addChannel(ch), callsLabel(ch.Data.CallsStarted, ch.Data.CallsFailed))
+	}
+	for _, group := range t.groupSubchannels(subchannels) {
+		if len(group) == 1 {
+			subch := group[0]
+			t.edge(id, t.addSubchannel(subch), callsLabel(subch.Data.CallsStarted, subch.Data.CallsFailed))
+			continue
+		}
+		t.addSubchannelGroup(id, group)
+	}
+	for _, socket := range sockets {
+		t.edge(id, t.addSocket(socket), streamsLabel(socket))
+	}
+}
+
+// groupSubchannels groups the subchannels by target when the graph is collapsed,
+// in the order of their first subchannel.
+func (t *topology) groupSubchannels(subchannels []*SubchannelNode) [][]*SubchannelNode {
+	var groups [][]*SubchannelNode
+	index := make(map[string]int)
+	for _, subch := range subchannels {
+		i, ok := index[subch.Data.Target]
+		if !t.collapse || !ok {
+			i = len(groups)
+			index[subch.Data.Target] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], subch)
+	}
+	return groups
+}
+
+// addSubchannelGroup adds the subchannels to the same target of the channel or subchannel
+// parent as one node, in the worst of their states, with the count of their sockets. The
+// channels and subchannels nested under them hang from the group node.
+func (t *topology) addSubchannelGroup(parent string, group []*SubchannelNode) {
+	var started, failed int64
+	var sockets int
+	var channels []*ChannelNode
+	var subchannels []*SubchannelNode
+	state := ""
+	for _, subch := range group {
+		started += subch.Data.CallsStarted
+		failed += subch.Data.CallsFailed
+		if s := subch.Data.GetState().GetState().String(); stateRank[s] > stateRank[state] {
+			state = s
+		}
+		sockets += len(subch.Sockets)
+		channels = append(channels, subch.Channels...)
+		subchannels = append(subchannels, subch.Subchannels...)
+	}
+
+	first := group[0].Ref.SubchannelId
+	label := []string{fmt.Sprintf("%d subchannels", len(group)), group[0].Data.Target}
+	if sockets > 0 {
+		label = append(label, fmt.Sprintf("%d sockets", sockets))
+	}
+	id := t.node(fmt.Sprintf("subchannels%d", first), graphSubchannel, state, label...)
+	t.edge(parent, id, callsLabel(started, failed))
+	t.addChildren(id, channels, subchannels, nil)
+}
+
+func (t *topology) addServer(node *ServerNode) string {
+	label := []string{fmt.Sprintf("server %d", node.Ref.ServerId)}
+	if node.Ref.Name != "" {
+		label = append(label, node.Ref.Name)
+	}
+	id := t.node(fmt.Sprintf("server%d", node.Ref.ServerId), graphServer, "", label...)
+	for _, lis := range node.ListenSockets {
+		lid := t.node(fmt.Sprintf("socket%d", lis.Socket.Ref.SocketId), graphListen, "",
+			fmt.Sprintf("listen %d", lis.Socket.Ref.SocketId), decorateEmpty(lis.Socket.LocalAddress))
+		t.edge(id, lid, "")
+		for _, socket := range lis.Accepted {
+			t.edge(lid, t.addSocket(socket), streamsLabel(socket))
+		}
+	}
+	for _, socket := range node.Sockets {
+		t.edge(id, t.addSocket(socket), streamsLabel(socket))
+	}
+	return id
+}
+
+// addSocket adds socket and its remote address, which the sockets connected to the same
+// peer share.
+func (t *topology) addSocket(socket *SocketView) string {
+	id := t.node(fmt.Sprintf("socket%d", socket.Ref.SocketId), graphSocket, "",
+		fmt.Sprintf("socket %d", socket.Ref.SocketId))
+	if socket.RemoteAddress != "" {
+		t.edge(id, t.node("address "+socket.RemoteAddress, graphAddress, "", socket.RemoteAddress), "")
+	}
+	return id
+}
+
+func callsLabel(started, failed int64) string {
+	return fmt.Sprintf("%d calls, %d failed", started, failed)
+}
+
+func streamsLabel(socket *SocketView) string {
+	return fmt.Sprintf("%d streams, %d failed", socket.Data.GetStreamsStarted(), socket.Data.GetStreamsFailed())
+}
+
+// stateRank orders the connectivity states from the healthiest to the worst.
+var stateRank = map[string]int{
+	"SHUTDOWN":          1,
+	"READY":             2,
+	"IDLE":              3,
+	"CONNECTING":        4,
+	"TRANSIENT_FAILURE": 5,
+}
+
+// stateFill is the fill color of the nodes in state.
+func stateFill(state string) string {
+	switch state {
+	case "READY":
+		return "#c8e6c9"
+	case "TRANSIENT_FAILURE":
+		return "#ffcdd2"
+	case "CONNECTING", "IDLE":
+		return "#fff9c4"
+	case "SHUTDOWN":
+		return "#e0e0e0"
+	}
+	return "#ffffff"
+}
+
+// dotShapes are the Graphviz shapes of the kinds of nodes.
+var dotShapes = map[string]string{
+	graphChannel:    "box",
+	graphSubchannel: "box",
+	graphServer:     "box3d",
+	graphListen:     "doubleoctagon",
+	graphSocket:     "ellipse",
+	graphAddress:    "note",
+}
+
+func (t *topology) writeDot(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph channelz {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [style=filled, fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, n := range t.nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s", dotQuote(n.id), dotQuote(n.label...), dotShapes[n.kind])
+		if n.state != "" {
+			fmt.Fprintf(&b, ", fillcolor=%s, tooltip=%s", dotQuote(stateFill(n.state)), dotQuote(n.state))
+		}
+		b.WriteString("];\n")
+	}
+	for _, e := range t.edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.from), dotQuote(e.to))
+		if e.label != "" {
+			fmt.Fprintf(&b, " [label=%s]", dotQuote(e.label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes the lines as a DOT string.
+func dotQuote(lines ...string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(line)
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
+
+// mermaidShapes are the Mermaid shapes of the kinds of nodes, as the brackets around their labels.
+var mermaidShapes = map[string][2]string{
+	graphChannel:    {"[", "]"},
+	graphSubchannel: {"[", "]"},
+	graphServer:     {"[[", "]]"},
+	graphListen:     {"{{", "}}"},
+	graphSocket:     {"([", "])"},
+	graphAddress:    {"[/", "/]"},
+}
+
+// mermaidStates are the states styled in the Mermaid flowcharts, as classes named after them.
+var mermaidStates = []string{"READY", "TRANSIENT_FAILURE", "CONNECTING", "IDLE", "SHUTDOWN"}
+
+func (t *topology) writeMermaid(w io.Writer) error {
+	// the ids of the nodes are not valid Mermaid ids, which are alphanumeric.
+	ids := make(map[string]string, len(t.nodes))
+	for i, n := range t.nodes {
+		ids[n.id] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range t.nodes {
+		shape := mermaidShapes[n.kind]
+		fmt.Fprintf(&b, "  %s%s%s%s", ids[n.id], shape[0], mermaidQuote(n.label...), shape[1])
+		if n.state != "" {
+			fmt.Fprintf(&b, ":::%s", n.state)
+		}
+		b.WriteString("\n")
+	}
+	for _, e := range t.edges {
+		if e.label == "" {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[e.from], ids[e.to])
+		} else {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.from], mermaidQuote(e.label), ids[e.to])
+		}
+	}
+	for _, state := range mermaidStates {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", state, stateFill(state))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidQuote quotes the lines as a Mermaid label, escaping the characters Mermaid would
+// read as markup.
+func mermaidQuote(lines ...string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(line)
+	}
+	return `"` + strings.Join(escaped, "<br/>") + `"`
+}
@@ -0,0 +1,174 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+func TestGraphDot(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestEventsClient(b)
+	if err := c.Graph(&Options{Format: FormatDot}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+digraph channelz {
+  rankdir=LR;
+  node [style=filled, fillcolor="#ffffff", fontname="Helvetica"];
+  edge [fontname="Helvetica", fontsize=10];
+  "channel100" [label="channel 100\nlb.test.com\n0 calls, 0 failed", shape=box, fillcolor="#fff9c4", tooltip="CONNECTING"];
+  "subchannel200" [label="subchannel 200\n10.0.0.1:443", shape=box, fillcolor="#ffcdd2", tooltip="TRANSIENT_FAILURE"];
+  "channel100" -> "subchannel200" [label="0 calls, 0 failed"];
+}
+`, b.String())
+}
+
+func TestGraphDotServers(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.Graph(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		`  "server1" [label="server 1\nserver1\n110 calls, 11 failed", shape=box3d];`,
+		`  "socket1" [label="listen 1\n127.0.1.2:9001", shape=doubleoctagon];`,
+		`  "server1" -> "socket1";`,
+		`  "socket1" -> "socket7" [label="10 streams, 1 failed"];`,
+		`  "socket7" -> "address 10.0.0.1:40000";`,
+		`  "channel1" -> "subchannel2" [label="120 calls, 12 failed"];`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing %s in\n%s", line, b.String())
+		}
+	}
+}
+
+func TestGraphFormatError(t *testing.T) {
+	c := newTestClient1(&bytes.Buffer{})
+	for _, opts := range []*Options{{Format: FormatWide}, {Json: true}} {
+		if err := c.Graph(opts, context.Background()); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestDotQuote(t *testing.T) {
+	if got, want := dotQuote(`a "b"`, `c\d`), `"a \"b\"\nc\\d"`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func newTestCollapseClient(b *bytes.Buffer) *Client {
+	channel := &channelzpb.Channel{
+		Ref: &channelzpb.ChannelRef{ChannelId: 1},
+		Data: &channelzpb.ChannelData{
+			State:  &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_READY},
+			Target: "dns:///backend",
+		},
+	}
+	var subchannels []*channelzpb.Subchannel
+	for i, state := range []channelzpb.ChannelConnectivityState_State{
+		channelzpb.ChannelConnectivityState_READY,
+		channelzpb.ChannelConnectivityState_TRANSIENT_FAILURE,
+		channelzpb.ChannelConnectivityState_READY,
+	} {
+		subch := &channelzpb.Subchannel{
+			Ref: &channelzpb.SubchannelRef{SubchannelId: int64(10 + i)},
+			Data: &channelzpb.ChannelData{
+				State:        &channelzpb.ChannelConnectivityState{State: state},
+				Target:       "10.0.0.1:443",
+				CallsStarted: 10,
+				CallsFailed:  int64(i),
+			},
+		}
+		subchannels = append(subchannels, subch)
+		channel.SubchannelRef = append(channel.SubchannelRef, subch.Ref)
+	}
+	// the first two subchannels have a socket each, the last one a nested channel.
+	var sockets []*channelzpb.Socket
+	for i, subch := range subchannels[:2] {
+		socket := &channelzpb.Socket{Ref: &channelzpb.SocketRef{SocketId: int64(20 + i)}}
+		sockets = append(sockets, socket)
+		subch.SocketRef = append(subch.SocketRef, socket.Ref)
+	}
+	nested := &channelzpb.Channel{
+		Ref: &channelzpb.ChannelRef{ChannelId: 2},
+		Data: &channelzpb.ChannelData{
+			State:  &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_IDLE},
+			Target: "dns:///nested",
+		},
+	}
+	subchannels[2].ChannelRef = append(subchannels[2].ChannelRef, nested.Ref)
+
+	return &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			topChannels: []*channelzpb.Channel{channel},
+			channels:    []*channelzpb.Channel{channel, nested},
+			subchannels: subchannels,
+			sockets:     sockets,
+		},
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestEventsClient(b)
+	if err := c.Graph(&Options{Format: FormatMermaid}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+flowchart LR
+  n0["channel 100<br/>lb.test.com<br/>0 calls, 0 failed"]:::CONNECTING
+  n1["subchannel 200<br/>10.0.0.1:443"]:::TRANSIENT_FAILURE
+  n0 -->|"0 calls, 0 failed"| n1
+  classDef READY fill:#c8e6c9
+  classDef TRANSIENT_FAILURE fill:#ffcdd2
+  classDef CONNECTING fill:#fff9c4
+  classDef IDLE fill:#fff9c4
+  classDef SHUTDOWN fill:#e0e0e0
+`, b.String())
+}
+
+func TestGraphCollapse(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestCollapseClient(b)
+	if err := c.Graph(&Options{Format: FormatMermaid, Collapse: true}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+flowchart LR
+  n0["channel 1<br/>dns:///backend<br/>0 calls, 0 failed"]:::READY
+  n1["3 subchannels<br/>10.0.0.1:443<br/>2 sockets"]:::TRANSIENT_FAILURE
+  n2["channel 2<br/>dns:///nested"]:::IDLE
+  n0 -->|"30 calls, 3 failed"| n1
+  n1 -->|"0 calls, 0 failed"| n2
+  classDef READY fill:#c8e6c9
+  classDef TRANSIENT_FAILURE fill:#ffcdd2
+  classDef CONNECTING fill:#fff9c4
+  classDef IDLE fill:#fff9c4
+  classDef SHUTDOWN fill:#e0e0e0
+`, b.String())
+
+	b.Reset()
+	if err := c.Graph(&Options{Format: FormatMermaid}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "<br/>10.0.0.1:443"); n != 3 {
+		t.Errorf("expected 3 subchannels without --collapse, got %d in\n%s", n, b.String())
+	}
+}
+
+func TestMermaidQuote(t *testing.T) {
+	if got, want := mermaidQuote(`a "b"`, "<none>"), `"a #quot;b#quot;<br/>#lt;none#gt;"`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,491 @@
+package channelz
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatJSONPath is the -o jsonpath=TEMPLATE output format.
+const FormatJSONPath = "jsonpath"
+
+// jsonPathTemplate is a compiled kubectl-like JSONPath template: text with {PATH} expressions,
+// {range PATH}...{end} loops and {"\n"} literals. The results of an expression are separated by
+// spaces, strings are printed as they are and the other values as JSON.
+//
+// The paths support $ for the root, @ and . for the current value, fields .a and ['a'], the
+// wildcards .* and [*], the recursive descent ..a, indexes [0] and [-1], slices [1:3] and
+// filters [?(@.a.b == "x")] with == != < <= > >=, or [?(@.a)] for the values having a.
+type jsonPathTemplate []jsonPathNode
+
+type jsonPathNode struct {
+	text string
+	path jsonPath
+	// body is set for the range nodes.
+	body jsonPathTemplate
+}
+
+func compileJSONPath(s string) (jsonPathTemplate, error) {
+	nodes, rest, err := parseJSONPathNodes(s, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected {end}")
+	}
+	return nodes, nil
+}
+
+// parseJSONPathNodes parses the nodes of s up to the end of s, or up to the {end} of a range,
+// and returns the text after it.
+func parseJSONPathNodes(s string, inRange bool) (jsonPathTemplate, string, error) {
+	var nodes jsonPathTemplate
+	for s != "" {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{text: s})
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{text: s[:open]})
+		}
+
+		closing := matchingBrace(s, open)
+		if closing < 0 {
+			return nil, "", fmt.Errorf("unclosed { in %q", s[open:])
+		}
+		expr := strings.TrimSpace(s[open+1 : closing])
+		s = s[closing+1:]
+
+		switch {
+		case expr == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("{end} without {range}")
+			}
+			return nodes, s, nil
+		case strings.HasPrefix(expr, "range "):
+			path, err := parseJSONPath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJSONPathNodes(s, true)
+			if err != nil {
+				return nil, "", err
+			}
+			if body == nil {
+				body = jsonPathTemplate{}
+			}
+			nodes = append(nodes, jsonPathNode{path: path, body: body})
+			s = rest
+		case strings.HasPrefix(expr, `"`):
+			text, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid literal %s", expr)
+			}
+			nodes = append(nodes, jsonPathNode{text: text})
+		default:
+			path, err := parseJSONPath(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path})
+		}
+	}
+	if inRange {
+		return nil, "", fmt.Errorf("{range} without {end}")
+	}
+	return nodes, "", nil
+}
+
+// matchingBrace returns the index of the } closing the { at open, skipping quoted strings.
+func matchingBrace(s string, open int) int {
+	var quote byte
+	depth := 0
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Execute writes the template evaluated over the document root to b, with cur the value
+// the relative paths start from.
+func (t jsonPathTemplate) Execute(b *bytes.Buffer, root, cur interface{}) error {
+	for _, node := range t {
+		switch {
+		case node.path == nil:
+			b.WriteString(node.text)
+		case node.body != nil:
+			values, err := node.path.eval(root, cur)
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				items := []interface{}{v}
+				if list, ok := v.([]interface{}); ok {
+					items = list
+				}
+				for _, item := range items {
+					if err := node.body.Execute(b, root, item); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			values, err := node.path.eval(root, cur)
+			if err != nil {
+				return err
+			}
+			for i, v := range values {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(formatDocumentValue(v))
+			}
+		}
+	}
+	return nil
+}
+
+// jsonPath is a compiled path, its steps applied in turn to every value of the previous one.
+type jsonPath []jsonPathStep
+
+type jsonPathStep func(root, v interface{}) ([]interface{}, error)
+
+func parseJSONPath(s string) (jsonPath, error) {
+	path := jsonPath{}
+	switch {
+	case strings.HasPrefix(s, "$"):
+		s = s[1:]
+		path = append(path, func(root, _ interface{}) ([]interface{}, error) { return []interface{}{root}, nil })
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	}
+
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := jsonPathName(s[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field after .. in %q", s)
+			}
+			path = append(path, recursiveStep(name))
+			s = rest
+		case strings.HasPrefix(s, "."):
+			name, rest := jsonPathName(s[1:])
+			s = rest
+			switch name {
+			case "":
+				// "." alone is the current value.
+			case "*":
+				path = append(path, wildcardStep)
+			default:
+				path = append(path, fieldStep(name))
+			}
+		case strings.HasPrefix(s, "["):
+			end := matchingBracket(s)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", s)
+			}
+			step, err := parseJSONPathBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, step)
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in the path", s)
+		}
+	}
+	return path, nil
+}
+
+// jsonPathName splits the field name at the start of s from the rest of the path.
+func jsonPathName(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// matchingBracket returns the index of the ] closing the [ at the start of s, skipping quoted strings.
+func matchingBracket(s string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseJSONPathBracket(s string) (jsonPathStep, error) {
+	switch {
+	case s == "*":
+		return wildcardStep, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		return parseJSONPathFilter(strings.TrimSpace(s[2 : len(s)-1]))
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		name, err := unquoteJSONPath(s)
+		if err != nil {
+			return nil, err
+		}
+		return fieldStep(name), nil
+	case strings.Contains(s, ":"):
+		return parseJSONPathSlice(s)
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index [%s]", s)
+	}
+	return func(_, v interface{}) ([]interface{}, error) {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		j := i
+		if j < 0 {
+			j += len(list)
+		}
+		if j < 0 || j >= len(list) {
+			return nil, nil
+		}
+		return []interface{}{list[j]}, nil
+	}, nil
+}
+
+func unquoteJSONPath(s string) (string, error) {
+	if strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2 {
+		return s[1 : len(s)-1], nil
+	}
+	name, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return name, nil
+}
+
+func parseJSONPathSlice(s string) (jsonPathStep, error) {
+	bounds := strings.SplitN(s, ":", 2)
+	var start, end *int
+	for i, b := range bounds {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+		n, err := strconv.Atoi(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice [%s]", s)
+		}
+		if i == 0 {
+			start = &n
+		} else {
+			end = &n
+		}
+	}
+
+	return func(_, v interface{}) ([]interface{}, error) {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		from, to := sliceBounds(len(list), start, end)
+		return list[from:to], nil
+	}, nil
+}
+
+// sliceBounds returns the bounds of the slice [start:end] of a list of n elements, as in
+// Python: the negative bounds count from the end, the missing ones are the ends of the list
+// and the bounds out of the list are clamped to it.
+func sliceBounds(n int, start, end *int) (from, to int) {
+	clamp := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > n {
+			return n
+		}
+		return i
+	}
+	from, to = clamp(start, 0), clamp(end, n)
+	if from > to {
+		to = from
+	}
+	return from, to
+}
+
+func parseJSONPathFilter(s string) (jsonPathStep, error) {
+	var op, left, right string
+	for _, o := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if i := strings.Index(s, o); i >= 0 {
+			op, left, right = o, strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(o):])
+			break
+		}
+	}
+	if op == "" {
+		left = s
+	}
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("the filter %q must start with @", s)
+	}
+	path, err := parseJSONPath(left)
+	if err != nil {
+		return nil, err
+	}
+
+	var literal interface{}
+	if op != "" {
+		switch {
+		case strings.HasPrefix(right, "'") || strings.HasPrefix(right, `"`):
+			if literal, err = unquoteJSONPath(right); err != nil {
+				return nil, err
+			}
+		case right == "true" || right == "false":
+			literal = right == "true"
+		case right == "null":
+		default:
+			f, err := strconv.ParseFloat(right, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q in the filter %q", right, s)
+			}
+			literal = f
+		}
+	}
+
+	return func(root, v interface{}) ([]interface{}, error) {
+		var out []interface{}
+		for _, item := range jsonPathChildren(v) {
+			values, err := path.eval(root, item)
+			if err != nil {
+				return nil, err
+			}
+			for _, x := range values {
+				if op == "" || compareWith(op, x, literal) {
+					out = append(out, item)
+					break
+				}
+			}
+		}
+		return out, nil
+	}, nil
+}
+
+func compareWith(op string, l, r interface{}) bool {
+	c := compareValues(l, r)
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<=":
+		return c <= 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	}
+	return c > 0
+}
+
+// jsonPathChildren returns the elements of an array, or the field values of an object.
+func jsonPathChildren(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case []interface{}:
+		return x
+	case map[string]interface{}:
+		children, _ := iterateQuery(x)
+		return children
+	}
+	return nil
+}
+
+func wildcardStep(_, v interface{}) ([]interface{}, error) {
+	return jsonPathChildren(v), nil
+}
+
+func fieldStep(name string) jsonPathStep {
+	return func(_, v interface{}) ([]interface{}, error) {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		if field := lookupField(obj, name); field != nil {
+			return []interface{}{field}, nil
+		}
+		return nil, nil
+	}
+}
+
+// recursiveStep returns the fields name of v and of all its descendants.
+func recursiveStep(name string) jsonPathStep {
+	field := fieldStep(name)
+	var descend func(root, v interface{}) []interface{}
+	descend = func(root, v interface{}) []interface{} {
+		found, _ := field(root, v)
+		for _, child := range jsonPathChildren(v) {
+			found = append(found, descend(root, child)...)
+		}
+		return found
+	}
+	return func(root, v interface{}) ([]interface{}, error) {
+		return descend(root, v), nil
+	}
+}
+
+func (p jsonPath) eval(root, cur interface{}) ([]interface{}, error) {
+	values := []interface{}{cur}
+	for _, step := range p {
+		var next []interface{}
+		for _, v := range values {
+			found, err := step(root, v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, found...)
+		}
+		values = next
+	}
+	return values, nil
+}
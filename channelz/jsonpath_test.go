@@ -0,0 +1,56 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestListJSONPath(t *testing.T) {
+	for _, tc := range []struct {
+		template string
+		expected string
+	}{
+		{`{.items[*].ref.name}`, `foo0 foo1`},
+		{`{range .items[*]}{.ref.channelId}{"\t"}{.data.target}{"\n"}{end}`, "0\tfoo0.test.com\n1\tfoo1.test.com"},
+		{`{.items[?(@.data.state.state == "READY")].ref.name}`, `foo0 foo1`},
+		{`{.items[?(@.data.calls_failed > 10)].ref.name}`, `foo1`},
+		{`{..target}`, `foo0.test.com foo1.test.com`},
+		{`{$.items[-1:].ref['name']} of {.items[0:1].ref.name}`, `foo1 of foo0`},
+		{`{.items[1].subchannel_ref[*].subchannel_id}`, `1 2 3 4`},
+		{`{.items[1].subchannelRef[*].subchannelId}`, `1 2 3 4`},
+	} {
+		b := &bytes.Buffer{}
+		c := newTestClient1(b)
+		if err := c.List(&Options{Format: FormatJSONPath + "=" + tc.template}, context.Background(), KindChannel); err != nil {
+			t.Errorf("%s: %v", tc.template, err)
+			continue
+		}
+		assertOutput(t, tc.expected, b.String())
+	}
+}
+
+func TestDescribeJSONPath(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	opts := &Options{Format: `jsonpath={.ref.name} {.data.last_call_started_timestamp}`}
+	if err := c.DescribeServer(opts, context.Background(), "server1"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `server1 2018-12-01T21:33:20.123456789Z`, b.String())
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	for _, template := range []string{`{.items[*]`, `{range .items[*]}{.a}`, `{end}`, `{.items[x]}`, `{.items[?(.a)]}`, `{items}`} {
+		if _, err := compileJSONPath(template); err == nil {
+			t.Errorf("expected an error for %s", template)
+		}
+	}
+
+	c := newTestClient1(&bytes.Buffer{})
+	opts := &Options{Format: "jsonpath={.items}", Query: ".items"}
+	if err := c.List(opts, context.Background(), KindChannel); err == nil {
+		t.Error("expected an error combining --query and -o jsonpath")
+	}
+}
@@ -0,0 +1,424 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// KindServerSocket lists only the sockets accepted by the servers.
+const KindServerSocket = "serversocket"
+
+// column is a column of the list tables. The rate columns are shown with --rate only,
+// the wide ones with -o wide only. paint, when set, colors the cells of the aligned tables.
+type column struct {
+	name  string
+	value func(now time.Time, row *Row) string
+	// number, when set, is the numeric value of the cell, which the report sorts by.
+	number   func(now time.Time, row *Row) float64
+	paint    func(p palette, row *Row, cell string) string
+	rateOnly bool
+	wide     bool
+}
+
+func intColumn(name string, v func(row *Row) int64) column {
+	return column{
+		name:   name,
+		value:  func(_ time.Time, row *Row) string { return fmt.Sprint(v(row)) },
+		number: func(_ time.Time, row *Row) float64 { return float64(v(row)) },
+	}
+}
+
+func stringColumn(name string, v func(row *Row) string) column {
+	return column{name: name, value: func(_ time.Time, row *Row) string { return decorateEmpty(v(row)) }}
+}
+
+func paintColumn(col column, paint func(p palette, row *Row, cell string) string) column {
+	col.paint = paint
+	return col
+}
+
+func wideColumn(col column) column {
+	col.wide = true
+	return col
+}
+
+var (
+	idColumn    = intColumn("ID", func(row *Row) int64 { return row.ID })
+	nameColumn  = stringColumn("Name", func(row *Row) string { return row.Name })
+	stateColumn = paintColumn(stringColumn("State", func(row *Row) string { return row.State }),
+		func(p palette, _ *Row, cell string) string { return p.state(cell) })
+	targetColumn  = wideColumn(stringColumn("Target", func(row *Row) string { return row.Target }))
+	callsColumn   = intColumn("Calls", func(row *Row) int64 { return row.Started })
+	successColumn = intColumn("Success", func(row *Row) int64 { return row.Succeeded })
+	failColumn    = paintColumn(intColumn("Fail", func(row *Row) int64 { return row.Failed }),
+		func(p palette, row *Row, cell string) string { return p.failed(float64(row.Failed), cell) })
+	failRateColumn = column{
+		name: "Fail/s", rateOnly: true,
+		value: func(_ time.Time, row *Row) string { return formatRate(row.Rate.Failed) },
+		paint: func(p palette, row *Row, cell string) string { return p.failed(row.Rate.Failed, cell) },
+	}
+
+	callRateColumns = []column{
+		{name: "Calls/s", rateOnly: true, value: func(_ time.Time, row *Row) string { return formatRate(row.Rate.Calls) }},
+		failRateColumn,
+		{name: "Err%", rateOnly: true, value: func(_ time.Time, row *Row) string { return formatRatio(row.Rate.ErrorRatio) }},
+	}
+	streamRateColumns = []column{
+		{name: "Streams/s", rateOnly: true, value: func(_ time.Time, row *Row) string { return formatRate(row.Rate.Calls) }},
+		failRateColumn,
+		{name: "Msgs/s", rateOnly: true, value: func(_ time.Time, row *Row) string { return formatRate(row.Rate.Messages) }},
+		{name: "Err%", rateOnly: true, value: func(_ time.Time, row *Row) string { return formatRatio(row.Rate.ErrorRatio) }},
+	}
+	socketWideColumns = []column{
+		wideColumn(stringColumn("RemoteName", func(row *Row) string { return row.Entity.(*channelzpb.Socket).RemoteName })),
+		wideColumn(intColumn("KeepAlives", func(row *Row) int64 { return row.Entity.(*channelzpb.Socket).Data.KeepAlivesSent })),
+		wideColumn(stringColumn("Security", func(row *Row) string { return securityModel(row.Entity.(*channelzpb.Socket)) })),
+	}
+)
+
+// lastColumn is the time since the last call of the rows, which sorts the rows without
+// calls last.
+func lastColumn(name string) column {
+	return column{
+		name:  name,
+		value: func(now time.Time, row *Row) string { return elapsedTimestamp(now, row.LastCall) },
+		number: func(now time.Time, row *Row) float64 {
+			d, ok := sinceTimestamp(now, row.LastCall)
+			if !ok {
+				return math.Inf(1)
+			}
+			return math.Abs(d.Seconds())
+		},
+	}
+}
+
+func securityModel(socket *channelzpb.Socket) string {
+	switch socket.GetSecurity().GetModel().(type) {
+	case *channelzpb.Security_Tls_:
+		return "tls"
+	case *channelzpb.Security_Other:
+		return "other"
+	}
+	return ""
+}
+
+// listColumns returns the columns of the list table of kind.
+func listColumns(kind string) []column {
+	var cols []column
+	switch kind {
+	case KindChannel:
+		cols = []column{
+			idColumn, nameColumn, stateColumn, targetColumn,
+			intColumn("Channel", func(row *Row) int64 { return int64(len(row.Entity.(*channelzpb.Channel).ChannelRef)) }),
+			intColumn("SubChannel", func(row *Row) int64 { return int64(len(row.Entity.(*channelzpb.Channel).SubchannelRef)) }),
+			wideColumn(intColumn("Socket", func(row *Row) int64 { return int64(len(row.Entity.(*channelzpb.Channel).SocketRef)) })),
+			callsColumn, successColumn, failColumn,
+			lastColumn("LastCall"),
+		}
+		cols = append(cols, callRateColumns...)
+	case KindSubchannel:
+		cols = []column{
+			idColumn,
+			{name: "Channel", value: func(_ time.Time, row *Row) string { return ownerID(row) }},
+			stringColumn("Name", func(row *Row) string { return row.DisplayName() }),
+			stateColumn, targetColumn,
+			intColumn("Socket", func(row *Row) int64 { return int64(len(row.Entity.(*channelzpb.Subchannel).SocketRef)) }),
+			callsColumn, successColumn, failColumn,
+			lastColumn("LastCall"),
+		}
+		cols = append(cols, callRateColumns...)
+	case KindServer:
+		cols = []column{
+			idColumn, nameColumn,
+			stringColumn("LocalAddr", func(row *Row) string { return row.Local }),
+			wideColumn(intColumn("ListenSocket", func(row *Row) int64 { return int64(len(row.Entity.(*channelzpb.Server).ListenSocket)) })),
+			callsColumn, successColumn, failColumn,
+			lastColumn("LastCall"),
+		}
+		cols = append(cols, callRateColumns...)
+	case KindServerSocket:
+		cols = []column{
+			idColumn,
+			{name: "ServerID", value: func(_ time.Time, row *Row) string { return ownerID(row) }},
+			nameColumn,
+			stringColumn("RemoteName", func(row *Row) string { return row.Entity.(*channelzpb.Socket).RemoteName }),
+			stringColumn("Local", func(row *Row) string { return row.Local }),
+			stringColumn("Remote", func(row *Row) string { return row.Remote }),
+			intColumn("Started", func(row *Row) int64 { return row.Started }),
+			successColumn, failColumn,
+			lastColumn("LastStream"),
+		}
+		cols = append(cols, socketWideColumns[1:]...)
+		cols = append(cols, streamRateColumns...)
+	case KindSocket:
+		cols = []column{
+			idColumn,
+			{name: "Owner", value: func(_ time.Time, row *Row) string { return ownerString(row) }},
+			stringColumn("Local", func(row *Row) string { return row.Local }),
+			stringColumn("Remote", func(row *Row) string { return row.Remote }),
+			intColumn("Started", func(row *Row) int64 { return row.Started }),
+			successColumn, failColumn,
+			intColumn("Msgs", func(row *Row) int64 { return row.Messages }),
+			lastColumn("LastActivity"),
+		}
+		cols = append(cols, socketWideColumns...)
+		cols = append(cols, streamRateColumns...)
+	}
+	return cols
+}
+
+func ownerID(row *Row) string {
+	if row.Owner == nil {
+		return "-"
+	}
+	return fmt.Sprint(row.Owner.ID)
+}
+
+func ownerString(row *Row) string {
+	return entityRefString(row.Owner)
+}
+
+// entityRefString formats ref as KIND/ID, - when nil.
+func entityRefString(ref *EntityRef) string {
+	if ref == nil {
+		return "-"
+	}
+	return ref.key()
+}
+
+func formatRate(v float64) string {
+	return fmt.Sprintf("%.1f", v)
+}
+
+func formatRatio(v float64) string {
+	if v < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*v)
+}
+
+// List prints a table of the entities of kind which pass opts.Filter. With opts.Rate, the entities
+// are sampled twice opts.Rate apart, and the per second rates of the window are added to the table.
+// The rows are printed as they are visited, unless opts.SortBy, or a template or custom-columns
+// format, requires them all first.
+func (cc *Client) List(opts *Options, ctx context.Context, kind string) error {
+	filter, err := opts.Filter.compile()
+	if err != nil {
+		return err
+	}
+	less, err := rowSort(opts.SortBy, opts.Reverse)
+	if err != nil {
+		return err
+	}
+
+	document := opts.document()
+	// the ndjson lines are streamed, one per row, while the JSON and YAML documents
+	// and the queries need all the rows at once.
+	whole := document && opts.Format != FormatNDJSON
+	var table tableWriter
+	var printer *rowPrinter
+	if !document {
+		if printer, err = newRowPrinter(cc.w, opts.Format); err != nil {
+			return err
+		}
+		if printer == nil {
+			if table, err = newTableWriter(cc.w, opts.Format); err != nil {
+				return err
+			}
+		}
+	}
+
+	// only the aligned tables are colored, the other formats are meant for other programs.
+	var colors palette
+	if opts.Format == FormatTable || opts.Format == FormatWide {
+		colors = opts.palette()
+	}
+
+	var columns []column
+	for _, col := range listColumns(kind) {
+		if (!col.rateOnly || opts.Rate > 0) && (!col.wide || opts.Format == FormatWide) {
+			columns = append(columns, col)
+		}
+	}
+
+	var first map[string]*Row
+	var firstAt time.Time
+	if opts.Rate > 0 {
+		first = make(map[string]*Row)
+		err := cc.visitListRows(ctx, kind, func(row *Row) error {
+			first[row.Key()] = row
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		firstAt = timeNow()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.Rate):
+		}
+	}
+
+	if table != nil {
+		var names []string
+		for _, col := range columns {
+			names = append(names, col.name)
+		}
+		table.Header(names)
+	}
+
+	now := timeNow()
+	window := now.Sub(firstAt)
+	if window < opts.Rate {
+		window = opts.Rate
+	}
+	emit := func(row *Row) error {
+		if document {
+			return cc.encode(opts, rowDocument(row))
+		}
+
+		var cells []string
+		for _, col := range columns {
+			cell := col.value(now, row)
+			if col.paint != nil {
+				cell = col.paint(colors, row, cell)
+			}
+			cells = append(cells, cell)
+		}
+		table.Row(cells)
+		return nil
+	}
+
+	var rows []*Row
+	err = cc.visitListRows(ctx, kind, func(row *Row) error {
+		if !filter.Match(now, row) {
+			return nil
+		}
+		if first != nil {
+			rate := rowRate(first[row.Key()], row, window)
+			row.Rate = &rate
+		}
+
+		if less == nil && printer == nil && !whole {
+			return emit(row)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if less != nil {
+		sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+	}
+	if printer != nil {
+		return printer.Print(now, rows)
+	}
+	if whole {
+		items := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, rowDocument(row))
+		}
+		return cc.encode(opts, &listDocument{Items: items})
+	}
+	if less != nil {
+		for _, row := range rows {
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+	}
+	if table != nil {
+		return table.Flush()
+	}
+	return nil
+}
+
+// listDocument is the JSON document of the list rows, and the JSON view the queries are evaluated over.
+type listDocument struct {
+	Items []interface{} `json:"items"`
+}
+
+// rowSorts are the keys the list rows can be sorted by, in ascending order.
+var rowSorts = map[string]func(a, b *Row) bool{
+	"id":            func(a, b *Row) bool { return a.ID < b.ID },
+	"name":          func(a, b *Row) bool { return a.DisplayName() < b.DisplayName() },
+	"state":         func(a, b *Row) bool { return a.State < b.State },
+	"calls":         func(a, b *Row) bool { return a.Started < b.Started },
+	"failed":        func(a, b *Row) bool { return a.Failed < b.Failed },
+	"failure-ratio": func(a, b *Row) bool { return failureRatio(a) < failureRatio(b) },
+	"last-call":     func(a, b *Row) bool { return timestampLess(a.LastCall, b.LastCall) },
+	"remote":        func(a, b *Row) bool { return remoteLess(a.Remote, b.Remote) },
+}
+
+// RowSortKeys returns the keys accepted by --sort-by of list.
+func RowSortKeys() []string {
+	keys := make([]string, 0, len(rowSorts))
+	for k := range rowSorts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rowSort returns the ascending, or descending when reverse, order of the rows by key,
+// nil when key is empty and the rows are kept in the order they are visited.
+func rowSort(key string, reverse bool) (func(a, b *Row) bool, error) {
+	if key == "" {
+		return nil, nil
+	}
+	less, ok := rowSorts[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort key %q, expected one of %v", key, RowSortKeys())
+	}
+	if reverse {
+		return func(a, b *Row) bool { return less(b, a) }, nil
+	}
+	return less, nil
+}
+
+// remoteLess orders TCP addresses by IP and then port numerically, the other addresses as strings.
+func remoteLess(a, b string) bool {
+	ha, pa, errA := net.SplitHostPort(a)
+	hb, pb, errB := net.SplitHostPort(b)
+	ipA, ipB := net.ParseIP(ha), net.ParseIP(hb)
+	if errA != nil || errB != nil || ipA == nil || ipB == nil {
+		return a < b
+	}
+	if c := bytes.Compare(ipA.To16(), ipB.To16()); c != 0 {
+		return c < 0
+	}
+	portA, _ := strconv.Atoi(pa)
+	portB, _ := strconv.Atoi(pb)
+	return portA < portB
+}
+
+// timestampLess orders the timestamps, the missing ones first.
+func timestampLess(a, b *timestamp.Timestamp) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	return a.AsTime().Before(b.AsTime())
+}
+
+// visitListRows visits the rows of kind, where the server sockets are the sockets owned by servers.
+func (cc *Client) visitListRows(ctx context.Context, kind string, fn func(*Row) error) error {
+	if kind == KindServerSocket {
+		return cc.visitServerSockets(ctx, func(socket *channelzpb.Socket, owner *EntityRef) error {
+			return fn(socketRow(socket, owner))
+		})
+	}
+	return cc.visitRows(ctx, kind, fn)
+}
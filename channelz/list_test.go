@@ -0,0 +1,156 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+func TestListRate(t *testing.T) {
+	b := &bytes.Buffer{}
+	fake := &fakeChannelzClient{
+		topChannels: []*channelzpb.Channel{
+			newWatchChannel(1, 100, 10),
+			newWatchChannel(2, 50, 0),
+		},
+	}
+	c := &Client{
+		w: b,
+		cc: &hookChannelzClient{
+			fakeChannelzClient: fake,
+			hook: func(n int) {
+				if n == 2 {
+					fake.topChannels = []*channelzpb.Channel{
+						newWatchChannel(1, 120, 15),
+						newWatchChannel(2, 50, 0),
+					}
+				}
+			},
+		},
+	}
+
+	expected := `
+ID  Name    State  Channel  SubChannel  Calls  Success  Fail  LastCall  Calls/s  Fail/s  Err%
+1   <none>  READY  0        0           120    105      15    none      2000.0   500.0   25.0%
+2   <none>  READY  0        0           50     50       0     none      0.0      0.0     -
+`
+	if err := c.List(&Options{Rate: 10 * time.Millisecond}, context.Background(), KindChannel); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+
+	// the documents have the rates next to the fields of the entities.
+	b.Reset()
+	fake.topChannels = []*channelzpb.Channel{newWatchChannel(1, 100, 10), newWatchChannel(2, 50, 0)}
+	c.cc.(*hookChannelzClient).n = 0
+	opts := &Options{Rate: 10 * time.Millisecond, Query: `.items[] | [.ref.channel_id, .rate.calls, .rate.error_ratio]`}
+	if err := c.List(opts, context.Background(), KindChannel); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, `
+["1",2000,0.25]
+["2",0,-1]
+`, b.String())
+}
+
+func TestListSubchannels(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+
+	expected := `
+ID  Channel  Name  State  Socket  Calls  Success  Fail  LastCall
+0   0        bar0  READY  1       100    90       10    0ms
+1   1        bar1  READY  1       110    99       11    0ms
+2   1        bar2  READY  1       120    108      12    0ms
+3   1        bar3  READY  1       130    117      13    0ms
+4   1        bar4  READY  1       140    126      14    0ms
+`
+	if err := c.List(&Options{}, context.Background(), KindSubchannel); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestListSortBy(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+
+	expected := `
+ID  Channel  Name  State  Socket  Calls  Success  Fail  LastCall
+4   1        bar4  READY  1       140    126      14    0ms
+3   1        bar3  READY  1       130    117      13    0ms
+2   1        bar2  READY  1       120    108      12    0ms
+1   1        bar1  READY  1       110    99       11    0ms
+0   0        bar0  READY  1       100    90       10    0ms
+`
+	if err := c.List(&Options{SortBy: "failed", Reverse: true}, context.Background(), KindSubchannel); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+
+	b.Reset()
+	expected = `
+ID  Owner         Local           Remote                 Started  Success  Fail  Msgs  LastActivity
+7   server/1      127.0.1.2:9001  10.0.0.1:40000         10       9        1     0     0ms
+8   server/1      127.0.1.2:9001  10.0.0.2:40001         20       18       2     0     0ms
+9   server/1      127.0.1.2:9001  10.0.0.3:40002         30       27       3     0     0ms
+2   subchannel/0  127.0.1.2:9001  111.111.111.111:30000  0        0        0     0     none
+3   subchannel/1  127.0.1.2:9001  111.111.111.112:30001  0        0        0     0     none
+4   subchannel/2  127.0.1.2:9001  111.111.111.113:30002  0        0        0     0     none
+5   subchannel/3  127.0.1.2:9001  111.111.111.114:30003  0        0        0     0     none
+6   subchannel/4  127.0.1.2:9001  111.111.111.115:30004  0        0        0     0     none
+`
+	if err := c.List(&Options{SortBy: "remote"}, context.Background(), KindSocket); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+
+	if err := c.List(&Options{SortBy: "color"}, context.Background(), KindSocket); err == nil {
+		t.Error("expected an error for an unknown sort key")
+	}
+}
+
+func TestListFormats(t *testing.T) {
+	for _, tc := range []struct {
+		format   string
+		expected string
+	}{
+		{FormatWide, `
+ID  Name     LocalAddr       ListenSocket  Calls  Success  Fail  LastCall
+0   server0  127.0.1.2:9000  1             100    90       10    none
+1   server1  127.0.1.2:9001  1             110    99       11    0ms
+`},
+		{FormatCSV, `
+ID,Name,LocalAddr,Calls,Success,Fail,LastCall
+0,server0,127.0.1.2:9000,100,90,10,none
+1,server1,127.0.1.2:9001,110,99,11,0ms
+`},
+		{FormatTSV, `
+ID	Name	LocalAddr	Calls	Success	Fail	LastCall
+0	server0	127.0.1.2:9000	100	90	10	none
+1	server1	127.0.1.2:9001	110	99	11	0ms
+`},
+		{FormatMarkdown, `
+| ID | Name | LocalAddr | Calls | Success | Fail | LastCall |
+| --- | --- | --- | --- | --- | --- | --- |
+| 0 | server0 | 127.0.1.2:9000 | 100 | 90 | 10 | none |
+| 1 | server1 | 127.0.1.2:9001 | 110 | 99 | 11 | 0ms |
+`},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			c := newTestClient1(b)
+			if err := c.List(&Options{Format: tc.format}, context.Background(), KindServer); err != nil {
+				t.Fatal(err)
+			}
+			assertOutput(t, tc.expected, b.String())
+		})
+	}
+
+	if err := newTestClient1(&bytes.Buffer{}).List(&Options{Format: "xml"}, context.Background(), KindServer); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,59 @@
+package channelz
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nameMatcher matches channelz entity names against the name given on the command line,
+// which is an exact name, a glob pattern like 'pubsub*', or a regular expression.
+type nameMatcher struct {
+	exact bool
+	re    *regexp.Regexp
+	name  string
+}
+
+func newNameMatcher(name string, regex bool) (*nameMatcher, error) {
+	switch {
+	case regex:
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", name, err)
+		}
+		return &nameMatcher{re: re, name: name}, nil
+	case strings.ContainsAny(name, "*?"):
+		return &nameMatcher{re: globToRegexp(name), name: name}, nil
+	default:
+		return &nameMatcher{exact: true, name: name}, nil
+	}
+}
+
+// Match reports whether any of the names matches.
+func (m *nameMatcher) Match(names ...string) bool {
+	for _, name := range names {
+		if m.exact && name == m.name || m.re != nil && m.re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp converts a glob pattern, where * matches any run of characters
+// and ? matches any single character, to an anchored regular expression.
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
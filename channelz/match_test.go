@@ -0,0 +1,36 @@
+package channelz
+
+import "testing"
+
+func TestNameMatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		regex bool
+		input string
+		want  bool
+	}{
+		{"pubsub.googleapis.com:443", false, "pubsub.googleapis.com:443", true},
+		{"pubsub", false, "pubsub.googleapis.com:443", false},
+		{"pubsub*", false, "pubsub.googleapis.com:443", true},
+		{"*googleapis*", false, "dns:///spanner.googleapis.com:443", true},
+		{"foo?", false, "foo1", true},
+		{"foo?", false, "foo12", false},
+		{"foo.*", false, "foo12", false},
+		{"^foo[0-9]+$", true, "foo12", true},
+		{"spanner", true, "dns:///spanner.googleapis.com:443", true},
+	}
+
+	for _, tt := range tests {
+		m, err := newNameMatcher(tt.name, tt.regex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Match(tt.input); got != tt.want {
+			t.Errorf("%q (regex:%v) match %q: got %v, want %v", tt.name, tt.regex, tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := newNameMatcher("foo(", true); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
@@ -1,12 +1,63 @@
 package channelz
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
 
 type Options struct {
 	Address  string
 	Verbose  bool
 	Insecure bool
 	Json     bool
-	Input    io.Reader
-	Output   io.Writer
+	Yaml     bool
+	// Format is the -o output format of the tables, see FormatTable and the other formats.
+	Format string
+	// Query is a --query expression evaluated over the JSON view of the result, see query.
+	Query string
+	// Color is the --color mode: ColorAuto, which is the default, ColorAlways or ColorNever.
+	Color      string
+	MaxSockets int
+	Regex      bool
+	Unique     bool
+	Follow     bool
+	Interval   time.Duration
+	Rate       time.Duration
+	SortBy     string
+	Reverse    bool
+	Filter     RowFilter
+	Top        int
+	// WindowBelow and Idle are the thresholds of the flow stall symptoms.
+	WindowBelow int64
+	Idle        time.Duration
+	All         bool
+	// ExpiringWithin filters the certs to the ones expiring within it, when set.
+	ExpiringWithin time.Duration
+	// Collapse draws the subchannels of a channel to the same target as one node in graph.
+	Collapse  bool
+	Input     io.Reader
+	Output    io.Writer
+	ErrOutput io.Writer
+}
+
+// document reports whether the result is printed as a document, or evaluated as one,
+// instead of as a table.
+func (o *Options) document() bool {
+	return o.Json || o.Yaml || o.Query != "" || o.Format == FormatNDJSON || strings.HasPrefix(o.Format, FormatJSONPath+"=")
+}
+
+// palette returns the colors of the tables, trees and descriptions written to o.Output.
+func (o *Options) palette() palette {
+	return newPalette(o.Color, o.Output)
+}
+
+func (o *Options) warnf(format string, a ...interface{}) {
+	w := o.ErrOutput
+	if w == nil {
+		w = os.Stderr
+	}
+	_, _ = fmt.Fprintf(w, "warning: "+format+"\n", a...)
 }
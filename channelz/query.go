@@ -0,0 +1,963 @@
+package channelz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// query is a compiled --query expression: a jq-like filter producing a stream of values
+// from its input, the JSON view of the result of a command.
+//
+// It supports the identity ., fields .a.b and ."a b", indexes .[0] and .["a"], slices .[1:3],
+// iteration .[], the recursive descent .., ?, pipes |, commas, array construction [...],
+// parentheses, the literals, == != < <= > >=,
+// + - * /, and, or, and the functions select(f), map(f), length, keys, not, add, tonumber and
+// tostring. Fields match their protobuf or their JSON names, and the int64 fields, which the
+// JSON view quotes, compare and add up as numbers.
+type query func(v interface{}) ([]interface{}, error)
+
+func compileQuery(expr string) (query, error) {
+	tokens, err := tokenizeQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	q, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return q, nil
+}
+
+type queryTokenKind int
+
+const (
+	tokenPunct queryTokenKind = iota
+	tokenField
+	tokenIdent
+	tokenNumber
+	tokenString
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	// value is the value of the number, string and field tokens.
+	value interface{}
+}
+
+func isIdentRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
+
+func tokenizeQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '.' && i+1 < len(rs) && isIdentRune(rs[i+1], true):
+			j := i + 1
+			for j < len(rs) && isIdentRune(rs[j], false) {
+				j++
+			}
+			tokens = append(tokens, queryToken{kind: tokenField, text: string(rs[i:j]), value: string(rs[i+1 : j])})
+			i = j
+		case r == '.' && i+1 < len(rs) && rs[i+1] == '"':
+			str, n, err := scanQueryString(rs[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokenField, text: string(rs[i : i+1+n]), value: str})
+			i += 1 + n
+		case r == '"':
+			str, n, err := scanQueryString(rs[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokenString, text: string(rs[i : i+n]), value: str})
+			i += n
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.' || rs[j] == 'e' || rs[j] == 'E') {
+				j++
+			}
+			f, err := strconv.ParseFloat(string(rs[i:j]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", string(rs[i:j]))
+			}
+			tokens = append(tokens, queryToken{kind: tokenNumber, text: string(rs[i:j]), value: f})
+			i = j
+		case isIdentRune(r, true):
+			j := i
+			for j < len(rs) && isIdentRune(rs[j], false) {
+				j++
+			}
+			tokens = append(tokens, queryToken{kind: tokenIdent, text: string(rs[i:j])})
+			i = j
+		default:
+			op := string(r)
+			if i+1 < len(rs) {
+				switch two := string(rs[i : i+2]); two {
+				case "==", "!=", "<=", ">=", "..":
+					op = two
+				}
+			}
+			if !strings.Contains("==!=<=>=..|,()[]+-*/<>?:", op) || op == "!" || op == "=" {
+				return nil, fmt.Errorf("unexpected %q", op)
+			}
+			tokens = append(tokens, queryToken{kind: tokenPunct, text: op})
+			i += len([]rune(op))
+		}
+	}
+	return tokens, nil
+}
+
+// scanQueryString scans the JSON string at the start of rs, and returns it with its length.
+func scanQueryString(rs []rune) (string, int, error) {
+	for j := 1; j < len(rs); j++ {
+		switch rs[j] {
+		case '\\':
+			j++
+		case '"':
+			var s string
+			if err := json.Unmarshal([]byte(string(rs[:j+1])), &s); err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", string(rs[:j+1]))
+			}
+			return s, j + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string %s", string(rs))
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *queryParser) peek() queryToken {
+	if p.done() {
+		return queryToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token when it is the punctuation or the keyword text.
+func (p *queryParser) accept(text string) bool {
+	if t := p.peek(); !p.done() && (t.kind == tokenPunct || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.accept(text) {
+		if p.done() {
+			return fmt.Errorf("expected %q at the end", text)
+		}
+		return fmt.Errorf("expected %q, got %q", text, p.peek().text)
+	}
+	return nil
+}
+
+func (p *queryParser) parsePipe() (query, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("|") {
+		return left, nil
+	}
+	right, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return func(v interface{}) ([]interface{}, error) {
+		vs, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, v := range vs {
+			rs, err := right(v)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rs...)
+		}
+		return out, nil
+	}, nil
+}
+
+func (p *queryParser) parseComma() (query, error) {
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		left := q
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		q = func(v interface{}) ([]interface{}, error) {
+			ls, err := left(v)
+			if err != nil {
+				return nil, err
+			}
+			rs, err := right(v)
+			return append(ls, rs...), err
+		}
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseOr() (query, error) {
+	q, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		q = binaryQuery(q, right, func(l, r interface{}) (interface{}, error) { return truthy(l) || truthy(r), nil })
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseAnd() (query, error) {
+	q, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		q = binaryQuery(q, right, func(l, r interface{}) (interface{}, error) { return truthy(l) && truthy(r), nil })
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseComparison() (query, error) {
+	q, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		op := op
+		return binaryQuery(q, right, func(l, r interface{}) (interface{}, error) { return compareWith(op, l, r), nil }), nil
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseAdditive() (query, error) {
+	q, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.accept("+"):
+			op = "+"
+		case p.accept("-"):
+			op = "-"
+		default:
+			return q, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		q = binaryQuery(q, right, func(l, r interface{}) (interface{}, error) { return arithmetic(op, l, r) })
+	}
+}
+
+func (p *queryParser) parseMultiplicative() (query, error) {
+	q, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.accept("*"):
+			op = "*"
+		case p.accept("/"):
+			op = "/"
+		default:
+			return q, nil
+		}
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		q = binaryQuery(q, right, func(l, r interface{}) (interface{}, error) { return arithmetic(op, l, r) })
+	}
+}
+
+func (p *queryParser) parsePostfix() (query, error) {
+	// last is the last step, kept apart from the ones before it for ? to apply to it only.
+	var base query
+	last, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	push := func(step query) {
+		if base == nil {
+			base = last
+		} else {
+			base = pipeQuery(base, last)
+		}
+		last = step
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case !p.done() && t.kind == tokenField:
+			p.pos++
+			push(fieldQuery(t.value.(string)))
+		case !p.done() && t.kind == tokenPunct && t.text == "." && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "[":
+			p.pos++
+		case p.accept("["):
+			index, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			push(index)
+		case p.accept("?"):
+			last = tryQuery(last)
+		default:
+			if base == nil {
+				return last, nil
+			}
+			return pipeQuery(base, last), nil
+		}
+	}
+}
+
+// parseIndex parses the index after a [: ] for the iteration, a slice FROM:TO], where both
+// bounds are optional, or an expression then ].
+func (p *queryParser) parseIndex() (query, error) {
+	if p.accept("]") {
+		return iterateQuery, nil
+	}
+	var index query
+	if !p.accept(":") {
+		var err error
+		if index, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if !p.accept(":") {
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return indexQuery(index), nil
+		}
+	}
+
+	var end query
+	if !p.accept("]") {
+		var err error
+		if end, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+	return sliceQuery(index, end), nil
+}
+
+// indexQuery indexes the input with every value of index.
+func indexQuery(index query) query {
+	return func(v interface{}) ([]interface{}, error) {
+		keys, err := index(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, key := range keys {
+			r, err := indexValue(v, key)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, r)
+		}
+		return out, nil
+	}
+}
+
+// sliceQuery slices the input arrays and strings from start to end, either of them may be nil.
+func sliceQuery(start, end query) query {
+	bound := func(q query, v interface{}) (*int, error) {
+		if q == nil {
+			return nil, nil
+		}
+		values, err := q(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 1 || values[0] == nil {
+			return nil, nil
+		}
+		f, ok := toNumber(values[0])
+		if !ok {
+			return nil, fmt.Errorf("can not slice with %s", typeName(values[0]))
+		}
+		i := int(math.Floor(f))
+		return &i, nil
+	}
+	return func(v interface{}) ([]interface{}, error) {
+		from, err := bound(start, v)
+		if err != nil {
+			return nil, err
+		}
+		to, err := bound(end, v)
+		if err != nil {
+			return nil, err
+		}
+		switch x := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			i, j := sliceBounds(len(x), from, to)
+			return []interface{}{x[i:j]}, nil
+		case string:
+			rs := []rune(x)
+			i, j := sliceBounds(len(rs), from, to)
+			return []interface{}{string(rs[i:j])}, nil
+		}
+		return nil, fmt.Errorf("can not slice %s", typeName(v))
+	}
+}
+
+func (p *queryParser) parseTerm() (query, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of the query")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokenField:
+		return fieldQuery(t.value.(string)), nil
+	case tokenNumber, tokenString:
+		return constQuery(t.value), nil
+	case tokenIdent:
+		return p.parseFunction(t.text)
+	}
+
+	switch t.text {
+	case ".":
+		if p.accept("[") {
+			return p.parseIndex()
+		}
+		return identityQuery, nil
+	case "..":
+		return recurseQuery, nil
+	case "(":
+		q, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return q, p.expect(")")
+	case "[":
+		if p.accept("]") {
+			return constQuery([]interface{}{}), nil
+		}
+		q, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return func(v interface{}) ([]interface{}, error) {
+			items, err := q(v)
+			if items == nil {
+				items = []interface{}{}
+			}
+			return []interface{}{items}, err
+		}, nil
+	case "-":
+		q, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return binaryQuery(constQuery(0.0), q, func(l, r interface{}) (interface{}, error) { return arithmetic("-", l, r) }), nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (p *queryParser) parseFunction(name string) (query, error) {
+	switch name {
+	case "true":
+		return constQuery(true), nil
+	case "false":
+		return constQuery(false), nil
+	case "null":
+		return constQuery(nil), nil
+	case "select", "map":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if name == "select" {
+			return selectQuery(f), nil
+		}
+		return mapQuery(f), nil
+	}
+
+	f, ok := queryFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	return func(v interface{}) ([]interface{}, error) {
+		r, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{r}, nil
+	}, nil
+}
+
+// queryFunctions are the functions of the queries without arguments.
+var queryFunctions = map[string]func(v interface{}) (interface{}, error){
+	"length": func(v interface{}) (interface{}, error) {
+		switch x := v.(type) {
+		case nil:
+			return 0.0, nil
+		case string:
+			return float64(len([]rune(x))), nil
+		case []interface{}:
+			return float64(len(x)), nil
+		case map[string]interface{}:
+			return float64(len(x)), nil
+		}
+		if f, ok := toNumber(v); ok {
+			return math.Abs(f), nil
+		}
+		return nil, fmt.Errorf("%s has no length", typeName(v))
+	},
+	"keys": func(v interface{}) (interface{}, error) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]interface{}, len(keys))
+			for i, k := range keys {
+				out[i] = k
+			}
+			return out, nil
+		case []interface{}:
+			out := make([]interface{}, len(x))
+			for i := range x {
+				out[i] = float64(i)
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("%s has no keys", typeName(v))
+	},
+	"not": func(v interface{}) (interface{}, error) { return !truthy(v), nil },
+	"add": func(v interface{}) (interface{}, error) {
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("can not add the items of %s", typeName(v))
+		}
+		var sum interface{}
+		for _, item := range items {
+			var err error
+			if sum, err = arithmetic("+", sum, item); err != nil {
+				return nil, err
+			}
+		}
+		return sum, nil
+	},
+	"tonumber": func(v interface{}) (interface{}, error) {
+		if f, ok := toNumber(v); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("can not convert %s to a number", typeName(v))
+	},
+	"tostring": func(v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func identityQuery(v interface{}) ([]interface{}, error) { return []interface{}{v}, nil }
+
+func constQuery(c interface{}) query {
+	return func(interface{}) ([]interface{}, error) { return []interface{}{c}, nil }
+}
+
+func pipeQuery(left, right query) query {
+	return func(v interface{}) ([]interface{}, error) {
+		vs, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, v := range vs {
+			rs, err := right(v)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rs...)
+		}
+		return out, nil
+	}
+}
+
+func fieldQuery(name string) query {
+	return func(v interface{}) ([]interface{}, error) {
+		r, err := indexValue(v, name)
+		return []interface{}{r}, err
+	}
+}
+
+func iterateQuery(v interface{}) ([]interface{}, error) {
+	switch x := v.(type) {
+	case []interface{}:
+		return x, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, len(keys))
+		for i, k := range keys {
+			out[i] = x[k]
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("can not iterate over %s", typeName(v))
+}
+
+// recurseQuery returns the input then, recursively, every value under it, as in jq's ...
+func recurseQuery(v interface{}) ([]interface{}, error) {
+	out := []interface{}{v}
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		children, err := iterateQuery(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range children {
+			values, err := recurseQuery(c)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, values...)
+		}
+	}
+	return out, nil
+}
+
+// tryQuery returns no values instead of the errors of q, as in .[]? for the missing lists.
+func tryQuery(q query) query {
+	return func(v interface{}) ([]interface{}, error) {
+		values, err := q(v)
+		if err != nil {
+			return nil, nil
+		}
+		return values, nil
+	}
+}
+
+func selectQuery(f query) query {
+	return func(v interface{}) ([]interface{}, error) {
+		conds, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, c := range conds {
+			if truthy(c) {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	}
+}
+
+func mapQuery(f query) query {
+	return func(v interface{}) ([]interface{}, error) {
+		items, err := iterateQuery(v)
+		if err != nil {
+			return nil, err
+		}
+		out := []interface{}{}
+		for _, item := range items {
+			rs, err := f(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rs...)
+		}
+		return []interface{}{out}, nil
+	}
+}
+
+// binaryQuery applies op to every pair of the values of left and right.
+func binaryQuery(left, right query, op func(l, r interface{}) (interface{}, error)) query {
+	return func(v interface{}) ([]interface{}, error) {
+		ls, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := right(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, l := range ls {
+			for _, r := range rs {
+				x, err := op(l, r)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, x)
+			}
+		}
+		return out, nil
+	}
+}
+
+// indexValue returns the field key of the object v, or the element key of the array v.
+func indexValue(v, key interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("can not index an object with %s", typeName(key))
+		}
+		return lookupField(x, name), nil
+	case []interface{}:
+		f, ok := key.(float64)
+		if !ok {
+			return nil, fmt.Errorf("can not index an array with %s", typeName(key))
+		}
+		i := int(f)
+		if i < 0 {
+			i += len(x)
+		}
+		if i < 0 || i >= len(x) {
+			return nil, nil
+		}
+		return x[i], nil
+	}
+	return nil, fmt.Errorf("can not index %s", typeName(v))
+}
+
+// normalizeField lets the fields be named with their protobuf names, as in the documents,
+// or their JSON ones: channel_id and channelId are the same field.
+func normalizeField(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// lookupField returns the field name of the object, matching its protobuf or its JSON name.
+func lookupField(obj map[string]interface{}, name string) interface{} {
+	if v, ok := obj[name]; ok {
+		return v
+	}
+	for k, v := range obj {
+		if normalizeField(k) == normalizeField(name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func truthy(v interface{}) bool {
+	return v != nil && v != false
+}
+
+// toNumber returns v as a number. The strings of numbers are numbers too, as protojson quotes
+// the int64 values.
+func toNumber(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// typeOrder orders the values of different types: null, booleans, numbers, strings, arrays, objects.
+func typeOrder(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64, json.Number:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	}
+	return 5
+}
+
+// compareValues orders l and r as jq does, comparing a number and the string of a number as numbers.
+func compareValues(l, r interface{}) int {
+	lo, ro := typeOrder(l), typeOrder(r)
+	if (lo == 2 || ro == 2) && lo != ro {
+		lf, lok := toNumber(l)
+		rf, rok := toNumber(r)
+		if lok && rok {
+			lo, ro = 2, 2
+			l, r = lf, rf
+		}
+	}
+	if lo != ro {
+		return lo - ro
+	}
+
+	switch lo {
+	case 1:
+		lb, rb := l.(bool), r.(bool)
+		switch {
+		case lb == rb:
+			return 0
+		case !lb:
+			return -1
+		}
+		return 1
+	case 2:
+		lf, _ := toNumber(l)
+		rf, _ := toNumber(r)
+		switch {
+		case lf < rf:
+			return -1
+		case lf > rf:
+			return 1
+		}
+		return 0
+	case 3:
+		return strings.Compare(l.(string), r.(string))
+	case 4:
+		la, ra := l.([]interface{}), r.([]interface{})
+		for i := 0; i < len(la) && i < len(ra); i++ {
+			if c := compareValues(la[i], ra[i]); c != 0 {
+				return c
+			}
+		}
+		return len(la) - len(ra)
+	case 5:
+		ld, _ := json.Marshal(l)
+		rd, _ := json.Marshal(r)
+		return strings.Compare(string(ld), string(rd))
+	}
+	return 0
+}
+
+func arithmetic(op string, l, r interface{}) (interface{}, error) {
+	if op == "+" {
+		switch {
+		case l == nil:
+			return r, nil
+		case r == nil:
+			return l, nil
+		}
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if lok && rok {
+			if lf, ok := toNumber(ls); ok {
+				if rf, ok := toNumber(rs); ok {
+					return lf + rf, nil
+				}
+			}
+			return ls + rs, nil
+		}
+		la, lok := l.([]interface{})
+		ra, rok := r.([]interface{})
+		if lok && rok {
+			return append(append([]interface{}{}, la...), ra...), nil
+		}
+	}
+
+	lf, lok := toNumber(l)
+	rf, rok := toNumber(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("can not apply %s to %s and %s", op, typeName(l), typeName(r))
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	}
+	if rf == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	return lf / rf, nil
+}
@@ -0,0 +1,102 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"items": [
+		{"ref": {"channel_id": "1", "name": "a"}, "data": {"calls_failed": "2", "calls_started": "10"}},
+		{"ref": {"channel_id": "2", "name": "b"}, "data": {"calls_failed": "0", "calls_started": "5"}}
+	]}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		query    string
+		expected string
+	}{
+		{`.items[].ref.name`, `"a" "b"`},
+		{`.items[] | select(.data.calls_failed > 0) | .ref.channelId`, `"1"`},
+		{`.items[0].data.calls_failed / .items[0].data.calls_started`, `0.2`},
+		{`[.items[].data.calls_started] | add`, `15`},
+		{`.items | length`, `2`},
+		{`.items[-1]["ref"].name`, `"b"`},
+		{`.items[] | .ref.name == "a" and (.data.calls_failed | tonumber) > 1`, `true false`},
+		{`.items | map(.ref.name)`, `["a","b"]`},
+		{`.items[0].ref | keys`, `["channel_id","name"]`},
+		{`.missing[]?`, ``},
+		{`.items[0].ref.name, -1, null, not`, `"a" -1 null false`},
+		{`.items[1:] | map(.ref.name)`, `["b"]`},
+		{`.items[:-1][].ref.channelId`, `"1"`},
+		{`.items[0].ref.name[0:1], .missing[1:]`, `"a" null`},
+		{`[.. | .channelId?] | map(select(. != null))`, `["1","2"]`},
+		{`[..] | length`, `16`},
+	} {
+		q, err := compileQuery(tc.query)
+		if err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+			continue
+		}
+		results, err := q(doc)
+		if err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+			continue
+		}
+		var out []string
+		for _, r := range results {
+			data, _ := json.Marshal(r)
+			out = append(out, string(data))
+		}
+		if got := strings.Join(out, " "); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, query := range []string{`.items[`, `.a |`, `foo`, `.a = 1`, `"unterminated`, `select(.a`} {
+		if _, err := compileQuery(query); err == nil {
+			t.Errorf("expected an error for %s", query)
+		}
+	}
+
+	q, err := compileQuery(`.missing[]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q(map[string]interface{}{}); err == nil {
+		t.Error("expected an error iterating over null")
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	opts := &Options{Query: `.items[] | select(.data.calls_failed >= 11) | .ref.name`}
+	if err := c.List(opts, context.Background(), KindServer); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `"server1"`, b.String())
+}
+
+func TestTreeQuery(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	opts := &Options{Query: `.[].listen_sockets[].accepted[]?.remote_address`}
+	if err := c.TreeServers(opts, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+"10.0.0.1:40000"
+"10.0.0.2:40001"
+"10.0.0.3:40002"
+`, b.String())
+}
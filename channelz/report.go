@@ -0,0 +1,526 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// report is the data of the HTML report.
+type report struct {
+	Address  string
+	Time     string
+	Topology *svgDiagram
+	Tables   []*reportTable
+	Traces   []*reportTrace
+	Certs    []*CertRow
+}
+
+// reportTable is a sortable table of the entities of a kind.
+type reportTable struct {
+	Title   string
+	Columns []string
+	Rows    [][]reportCell
+}
+
+// reportCell is a cell of a report table, styled with Class. Sort, when set, is the number
+// the cell sorts by, as "100ms" or "2s" do not sort as text.
+type reportCell struct {
+	Text  string
+	Class string
+	Sort  string
+}
+
+// reportTrace is the trace of a channel, subchannel or server.
+type reportTrace struct {
+	Title  string
+	State  string
+	Logged int64
+	Events []*channelzpb.ChannelTraceEvent
+}
+
+// reportTables are the tables of the report, in order.
+var reportTables = []struct {
+	title string
+	kind  string
+}{
+	{"Channels", KindChannel},
+	{"Subchannels", KindSubchannel},
+	{"Servers", KindServer},
+	{"Sockets", KindSocket},
+}
+
+// Report writes a self-contained HTML report of every channelz entity: sortable tables of
+// the channels, subchannels, servers and sockets, the trace events of the channels,
+// subchannels and servers, the certificates of the sockets and a diagram of the topology.
+// Everything is derived from one snapshot, so the sections agree with each other.
+func (cc *Client) Report(opts *Options, ctx context.Context) error {
+	s, err := cc.TakeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	now := s.Time
+	r := &report{Address: opts.Address, Time: now.UTC().Format(time.RFC3339)}
+	x := newSnapshotIndex(s)
+
+	t := &topology{}
+	for _, channel := range s.Channels {
+		if !x.nested[channel.Ref.ChannelId] {
+			node := x.channelTree(channel)
+			t.root(t.addChannel(node), callsLabel(node.Data.CallsStarted, node.Data.CallsFailed))
+		}
+	}
+	for _, server := range s.Servers {
+		node := x.serverTree(server)
+		t.root(t.addServer(node), callsLabel(node.Data.CallsStarted, node.Data.CallsFailed))
+	}
+	r.Topology = t.layout()
+
+	rows := make(map[string][]*Row)
+	for _, channel := range s.Channels {
+		rows[KindChannel] = append(rows[KindChannel], channelRow(channel))
+	}
+	for _, subch := range s.Subchannels {
+		rows[KindSubchannel] = append(rows[KindSubchannel], subchannelRow(subch, x.subchannelOwners[subch.Ref.SubchannelId]))
+	}
+	for _, server := range s.Servers {
+		var listenSocket *channelzpb.Socket
+		if len(server.ListenSocket) > 0 {
+			listenSocket = x.sockets[server.ListenSocket[0].SocketId]
+		}
+		rows[KindServer] = append(rows[KindServer], serverRow(server, listenSocket))
+	}
+	for _, socket := range s.Sockets {
+		rows[KindSocket] = append(rows[KindSocket], socketRow(socket, x.socketOwners[socket.Ref.SocketId]))
+	}
+	for _, tt := range reportTables {
+		table := &reportTable{Title: tt.title}
+		columns := listColumns(tt.kind)
+		for _, col := range columns {
+			if !col.rateOnly {
+				table.Columns = append(table.Columns, col.name)
+			}
+		}
+		for _, row := range rows[tt.kind] {
+			var cells []reportCell
+			for _, col := range columns {
+				if !col.rateOnly {
+					cell := reportCell{Text: col.value(now, row), Class: reportCellClass(col, row)}
+					if col.number != nil {
+						cell.Sort = sortNumber(col.number(now, row))
+					}
+					cells = append(cells, cell)
+				}
+			}
+			table.Rows = append(table.Rows, cells)
+		}
+		r.Tables = append(r.Tables, table)
+	}
+
+	for _, channel := range s.Channels {
+		r.addTrace(fmt.Sprintf("channel %d (%s)", channel.Ref.ChannelId, decorateEmpty(channel.Data.Target)),
+			channel.Data.GetState().GetState().String(), channel.Data.Trace)
+	}
+	for _, subch := range s.Subchannels {
+		r.addTrace(fmt.Sprintf("subchannel %d (%s)", subch.Ref.SubchannelId, decorateEmpty(subch.Data.Target)),
+			subch.Data.GetState().GetState().String(), subch.Data.Trace)
+	}
+	for _, server := range s.Servers {
+		r.addTrace(fmt.Sprintf("server %d (%s)", server.Ref.ServerId, decorateEmpty(server.Ref.Name)), "", server.Data.Trace)
+	}
+
+	for _, socket := range s.Sockets {
+		r.Certs = append(r.Certs, socketCertRows(now, socket, x.socketOwners[socket.Ref.SocketId])...)
+	}
+
+	return reportTemplate.Execute(cc.w, r)
+}
+
+// snapshotIndex indexes the entities of a snapshot by ID, with the owner of every
+// subchannel and socket and the channels nested under another entity.
+type snapshotIndex struct {
+	*Snapshot
+	channels         map[int64]*channelzpb.Channel
+	subchannels      map[int64]*channelzpb.Subchannel
+	sockets          map[int64]*channelzpb.Socket
+	nested           map[int64]bool
+	subchannelOwners map[int64]*EntityRef
+	socketOwners     map[int64]*EntityRef
+}
+
+func newSnapshotIndex(s *Snapshot) *snapshotIndex {
+	x := &snapshotIndex{
+		Snapshot:         s,
+		channels:         make(map[int64]*channelzpb.Channel),
+		subchannels:      make(map[int64]*channelzpb.Subchannel),
+		sockets:          make(map[int64]*channelzpb.Socket),
+		nested:           make(map[int64]bool),
+		subchannelOwners: make(map[int64]*EntityRef),
+		socketOwners:     make(map[int64]*EntityRef),
+	}
+	own := func(owner *EntityRef, channels []*channelzpb.ChannelRef, subchannels []*channelzpb.SubchannelRef, sockets []*channelzpb.SocketRef) {
+		for _, ref := range channels {
+			x.nested[ref.ChannelId] = true
+		}
+		for _, ref := range subchannels {
+			if x.subchannelOwners[ref.SubchannelId] == nil {
+				x.subchannelOwners[ref.SubchannelId] = owner
+			}
+		}
+		for _, ref := range sockets {
+			if x.socketOwners[ref.SocketId] == nil {
+				x.socketOwners[ref.SocketId] = owner
+			}
+		}
+	}
+
+	for _, channel := range s.Channels {
+		x.channels[channel.Ref.ChannelId] = channel
+		owner := &EntityRef{Kind: KindChannel, ID: channel.Ref.ChannelId, Name: entityName(channel.Ref.Name, channel.Data)}
+		own(owner, channel.ChannelRef, channel.SubchannelRef, channel.SocketRef)
+	}
+	for _, subch := range s.Subchannels {
+		x.subchannels[subch.Ref.SubchannelId] = subch
+		owner := &EntityRef{Kind: KindSubchannel, ID: subch.Ref.SubchannelId, Name: entityName(subch.Ref.Name, subch.Data)}
+		own(owner, subch.ChannelRef, subch.SubchannelRef, subch.SocketRef)
+	}
+	for _, socket := range s.Sockets {
+		x.sockets[socket.Ref.SocketId] = socket
+	}
+	for _, server := range s.Servers {
+		owner := &EntityRef{Kind: KindServer, ID: server.Ref.ServerId, Name: server.Ref.Name}
+		sockets := server.ListenSocket
+		for _, id := range s.ServerSockets[server.Ref.ServerId] {
+			sockets = append(sockets, &channelzpb.SocketRef{SocketId: id})
+		}
+		own(owner, nil, nil, sockets)
+	}
+	return x
+}
+
+// getSockets returns the sockets of refs in the snapshot.
+func (x *snapshotIndex) getSockets(refs []*channelzpb.SocketRef) []*channelzpb.Socket {
+	var sockets []*channelzpb.Socket
+	for _, ref := range refs {
+		if socket, ok := x.sockets[ref.SocketId]; ok {
+			sockets = append(sockets, socket)
+		}
+	}
+	return sockets
+}
+
+// channelTree is the tree of channel in the snapshot, as Client.channelTree.
+func (x *snapshotIndex) channelTree(channel *channelzpb.Channel) *ChannelNode {
+	return &ChannelNode{
+		Ref:         channel.Ref,
+		Data:        channel.Data,
+		Channels:    x.channelTrees(channel.ChannelRef),
+		Subchannels: x.subchannelTrees(channel.SubchannelRef),
+		Sockets:     newSocketViews(x.getSockets(channel.SocketRef)),
+	}
+}
+
+func (x *snapshotIndex) channelTrees(refs []*channelzpb.ChannelRef) []*ChannelNode {
+	var nodes []*ChannelNode
+	for _, ref := range refs {
+		if channel, ok := x.channels[ref.ChannelId]; ok {
+			nodes = append(nodes, x.channelTree(channel))
+		}
+	}
+	return nodes
+}
+
+func (x *snapshotIndex) subchannelTrees(refs []*channelzpb.SubchannelRef) []*SubchannelNode {
+	var nodes []*SubchannelNode
+	for _, ref := range refs {
+		subch, ok := x.subchannels[ref.SubchannelId]
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, &SubchannelNode{
+			Ref:         subch.Ref,
+			Data:        subch.Data,
+			Channels:    x.channelTrees(subch.ChannelRef),
+			Subchannels: x.subchannelTrees(subch.SubchannelRef),
+			Sockets:     newSocketViews(x.getSockets(subch.SocketRef)),
+		})
+	}
+	return nodes
+}
+
+// serverTree is the tree of server in the snapshot, as Client.serverTree.
+func (x *snapshotIndex) serverTree(server *channelzpb.Server) *ServerNode {
+	node := &ServerNode{Ref: server.Ref, Data: server.Data}
+	for _, socket := range x.getSockets(server.ListenSocket) {
+		node.ListenSockets = append(node.ListenSockets, &ListenSocketNode{Socket: newSocketView(socket)})
+	}
+	for _, id := range x.ServerSockets[server.Ref.ServerId] {
+		socket, ok := x.sockets[id]
+		if !ok {
+			continue
+		}
+		if lis := findListenSocket(node.ListenSockets, socket); lis != nil {
+			lis.Accepted = append(lis.Accepted, newSocketView(socket))
+		} else {
+			node.Sockets = append(node.Sockets, newSocketView(socket))
+		}
+	}
+	return node
+}
+
+func (r *report) addTrace(title, state string, trace *channelzpb.ChannelTrace) {
+	if trace == nil || len(trace.Events) == 0 {
+		return
+	}
+	r.Traces = append(r.Traces, &reportTrace{Title: title, State: state, Logged: trace.NumEventsLogged, Events: trace.Events})
+}
+
+// sortNumber formats n for the data-sort attribute, which parseFloat reads back.
+func sortNumber(n float64) string {
+	if math.IsInf(n, 1) {
+		return "Infinity"
+	}
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+// reportCellClass styles the states and the non-zero failure counts, as the colors of the tables.
+func reportCellClass(col column, row *Row) string {
+	switch col.name {
+	case stateColumn.name:
+		return "state " + row.State
+	case failColumn.name:
+		if row.Failed != 0 {
+			return "failed"
+		}
+	}
+	return ""
+}
+
+// svgDiagram is the topology laid out in columns, by depth from the roots, left to right.
+type svgDiagram struct {
+	Width, Height int
+	Nodes         []svgNode
+	Edges         []svgEdge
+}
+
+type svgNode struct {
+	X, Y, W, H int
+	Fill       string
+	State      string
+	Lines      []svgLine
+}
+
+type svgLine struct {
+	X, Y int
+	Text string
+}
+
+type svgEdge struct {
+	Path           string
+	LabelX, LabelY int
+	Label          string
+}
+
+// Sizes of the topology diagram, in pixels.
+const (
+	svgNodeWidth  = 200
+	svgLineHeight = 15
+	svgPadding    = 8
+	svgColumnGap  = 90
+	svgRowGap     = 14
+)
+
+// layout places the nodes of t in columns by their depth, in the order they were added,
+// which keeps the children of a node next to each other.
+func (t *topology) layout() *svgDiagram {
+	children := make(map[string][]string)
+	parents := make(map[string]int)
+	for _, e := range t.edges {
+		children[e.from] = append(children[e.from], e.to)
+		parents[e.to]++
+	}
+
+	depth := make(map[string]int)
+	var queue []string
+	for _, n := range t.nodes {
+		if parents[n.id] == 0 {
+			depth[n.id] = 0
+			queue = append(queue, n.id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if _, ok := depth[child]; !ok {
+				depth[child] = depth[id] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	d := &svgDiagram{}
+	bottoms := make(map[int]int)
+	boxes := make(map[string]int)
+	for _, n := range t.nodes {
+		col := depth[n.id]
+		h := len(n.label)*svgLineHeight + 2*svgPadding
+		node := svgNode{
+			X: svgRowGap + col*(svgNodeWidth+svgColumnGap), Y: bottoms[col] + svgRowGap,
+			W: svgNodeWidth, H: h,
+			Fill: stateFill(n.state), State: n.state,
+		}
+		for i, line := range n.label {
+			node.Lines = append(node.Lines, svgLine{X: node.X + svgPadding, Y: node.Y + svgPadding + (i+1)*svgLineHeight - 3, Text: line})
+		}
+		bottoms[col] = node.Y + h
+		boxes[n.id] = len(d.Nodes)
+		d.Nodes = append(d.Nodes, node)
+
+		if right := node.X + node.W + svgRowGap; right > d.Width {
+			d.Width = right
+		}
+		if bottom := node.Y + h + svgRowGap; bottom > d.Height {
+			d.Height = bottom
+		}
+	}
+
+	for _, e := range t.edges {
+		from, to := d.Nodes[boxes[e.from]], d.Nodes[boxes[e.to]]
+		x1, y1 := from.X+from.W, from.Y+from.H/2
+		x2, y2 := to.X, to.Y+to.H/2
+		mid := (x1 + x2) / 2
+		d.Edges = append(d.Edges, svgEdge{
+			Path:   fmt.Sprintf("M %d %d C %d %d, %d %d, %d %d", x1, y1, mid, y1, mid, y2, x2, y2),
+			LabelX: mid, LabelY: (y1+y2)/2 - 3,
+			Label: e.label,
+		})
+	}
+	return d
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"timestamp": stringTimestamp,
+	"severity":  prettyChannelTraceEventSeverity,
+	"lower":     strings.ToLower,
+	"join":      strings.Join,
+	"rfc3339":   func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	"owner":     entityRefString,
+}).Parse(reportHTML))
+
+const reportHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>channelz report{{with .Address}} of {{.}}{{end}}</title>
+<style>
+body { font: 14px/1.4 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 22px; margin-bottom: 4px; }
+h2 { font-size: 18px; margin-top: 32px; border-bottom: 1px solid #ddd; }
+.meta { color: #666; }
+table { border-collapse: collapse; margin: 8px 0; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; white-space: nowrap; }
+th { background: #f4f4f4; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:nth-child(even) td { background: #fafafa; }
+.state.READY { background: #c8e6c9 !important; }
+.state.TRANSIENT_FAILURE { background: #ffcdd2 !important; }
+.state.CONNECTING, .state.IDLE { background: #fff9c4 !important; }
+.state.SHUTDOWN { background: #e0e0e0 !important; }
+.failed { color: #c62828; font-weight: bold; }
+details { margin: 6px 0; }
+summary { cursor: pointer; }
+ol.timeline { list-style: none; border-left: 2px solid #ccc; margin: 6px 0 6px 8px; padding-left: 12px; }
+ol.timeline li { margin: 4px 0; }
+ol.timeline time { color: #666; font-family: monospace; margin-right: 8px; }
+.severity { display: inline-block; min-width: 70px; font-weight: bold; }
+.severity.error { color: #c62828; }
+.severity.warning { color: #b58900; }
+.severity.info { color: #2e7d32; }
+.diagram { overflow: auto; border: 1px solid #ddd; }
+.diagram text { font: 12px Helvetica, Arial, sans-serif; }
+.diagram .edge { fill: none; stroke: #888; }
+.diagram .label { fill: #555; font-size: 10px; text-anchor: middle; }
+.empty { color: #888; }
+</style>
+</head>
+<body>
+<h1>channelz report</h1>
+<p class="meta">{{with .Address}}{{.}}, {{end}}generated at {{.Time}}</p>
+
+<h2>Topology</h2>
+{{with .Topology}}{{if .Nodes}}<div class="diagram">
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M 0 0 L 10 5 L 0 10 z" fill="#888"/></marker></defs>
+{{range .Edges}}<path class="edge" d="{{.Path}}" marker-end="url(#arrow)"/>
+{{if .Label}}<text class="label" x="{{.LabelX}}" y="{{.LabelY}}">{{.Label}}</text>
+{{end}}{{end}}{{range .Nodes}}<g>{{with .State}}<title>{{.}}</title>{{end}}<rect x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="{{.H}}" rx="4" fill="{{.Fill}}" stroke="#555"/>
+{{range .Lines}}<text x="{{.X}}" y="{{.Y}}">{{.Text}}</text>{{end}}</g>
+{{end}}</svg>
+</div>{{else}}<p class="empty">no channels or servers</p>{{end}}{{end}}
+{{range .Tables}}
+<h2>{{.Title}}</h2>
+{{if .Rows}}<table class="sortable">
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td{{with .Class}} class="{{.}}"{{end}}{{with .Sort}} data-sort="{{.}}"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>{{else}}<p class="empty">none</p>{{end}}
+{{end}}
+<h2>Trace events</h2>
+{{range .Traces}}<details>
+<summary><span{{with .State}} class="state {{.}}"{{end}}>{{.Title}}</span>: {{len .Events}} events, {{.Logged}} logged</summary>
+<ol class="timeline">
+{{range .Events}}{{$severity := severity .Severity}}<li><time>{{timestamp .Timestamp}}</time><span class="severity {{lower $severity}}">{{$severity}}</span> {{.Description}}</li>
+{{end}}</ol>
+</details>
+{{else}}<p class="empty">none</p>
+{{end}}
+<h2>Certificates</h2>
+{{if .Certs}}<table class="sortable">
+<thead><tr><th>Socket</th><th>Owner</th><th>Remote</th><th>Side</th><th>Cipher</th><th>Subject</th><th>Issuer</th><th>SANs</th><th>Serial</th><th>NotBefore</th><th>NotAfter</th><th>DaysLeft</th></tr></thead>
+<tbody>
+{{range .Certs}}<tr><td>{{.Socket.ID}}</td><td>{{owner .Owner}}</td><td>{{.Remote}}</td><td>{{.Side}}</td><td>{{.Cipher}}</td>
+{{if .Certificate}}<td>{{.Subject}}</td><td>{{.Issuer}}</td><td>{{join .SANs ", "}}</td><td>{{.Serial}}</td><td>{{rfc3339 .NotBefore}}</td><td>{{rfc3339 .NotAfter}}</td><td{{if lt .DaysLeft 30}} class="failed"{{end}}>{{.DaysLeft}}</td>
+{{else}}<td colspan="7" class="failed">{{.Error}}</td>
+{{end}}</tr>
+{{end}}</tbody>
+</table>{{else}}<p class="empty">none</p>{{end}}
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, i) {
+    th.addEventListener("click", function () {
+      var asc = !th.classList.contains("asc");
+      table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+      th.classList.add(asc ? "asc" : "desc");
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var ca = a.cells[i], cb = b.cells[i];
+        var x = ca ? ca.textContent : "", y = cb ? cb.textContent : "";
+        var c;
+        if (ca && cb && ca.hasAttribute("data-sort") && cb.hasAttribute("data-sort")) {
+          var sx = parseFloat(ca.getAttribute("data-sort")), sy = parseFloat(cb.getAttribute("data-sort"));
+          c = sx === sy ? 0 : sx < sy ? -1 : 1;
+        } else {
+          var nx = parseFloat(x), ny = parseFloat(y);
+          c = !isNaN(nx) && !isNaN(ny) && String(nx) === x.trim() && String(ny) === y.trim() ? nx - ny : x.localeCompare(y);
+        }
+        return asc ? c : -c;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,96 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReport(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestEventsClient(b)
+	if err := c.Report(&Options{Address: "localhost:8000"}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	for _, fragment := range []string{
+		`<title>channelz report of localhost:8000</title>`,
+		`<p class="meta">localhost:8000, generated at 2018-12-01T21:33:20Z</p>`,
+		`<text x="22" y="34">channel 100</text>`,
+		`<thead><tr><th>ID</th><th>Name</th><th>State</th><th>Target</th>`,
+		`<td class="state CONNECTING">CONNECTING</td><td>lb.test.com</td>`,
+		`<summary><span class="state TRANSIENT_FAILURE">subchannel 200 (10.0.0.1:443)</span>: 2 events, 2 logged</summary>`,
+		`<span class="severity warning">WARNING</span> Subchannel Connectivity change to TRANSIENT_FAILURE</li>`,
+	} {
+		if !strings.Contains(out, fragment) {
+			t.Errorf("missing %s in\n%s", fragment, out)
+		}
+	}
+}
+
+func TestReportSelfContained(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestCertsClient(t, b)
+	if err := c.Report(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	if !strings.Contains(out, `<td>CN=server.test.com,O=test</td>`) {
+		t.Errorf("missing the certificate in\n%s", out)
+	}
+	for _, external := range []string{"<link", " src=", "href=", "@import", "url(http"} {
+		if strings.Contains(out, external) {
+			t.Errorf("unexpected external asset %q in\n%s", external, out)
+		}
+	}
+}
+
+func TestReportOneSnapshot(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	hook := &hookChannelzClient{fakeChannelzClient: c.cc.(*fakeChannelzClient), hook: func(int) {}}
+	c.cc = hook
+	if err := c.Report(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if hook.n != 1 {
+		t.Errorf("expected the top channels to be listed once, got %d", hook.n)
+	}
+	// the owners of the accepted sockets come from the snapshot too.
+	if out := b.String(); !strings.Contains(out, "<td>server/1</td>") {
+		t.Errorf("missing the owner of the accepted sockets in\n%s", out)
+	}
+}
+
+func TestReportSortKeys(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.Report(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the numbers and durations sort by their value, the entities without calls last.
+	out := b.String()
+	for _, fragment := range []string{
+		`<td data-sort="110">110</td>`,
+		`<td data-sort="Infinity">none</td>`,
+		`if (ca && cb && ca.hasAttribute("data-sort") && cb.hasAttribute("data-sort"))`,
+	} {
+		if !strings.Contains(out, fragment) {
+			t.Errorf("missing %s in\n%s", fragment, out)
+		}
+	}
+
+	last := lastColumn("LastCall")
+	row := &Row{LastCall: timestamppb.New(fixedTime.Add(-100 * time.Millisecond))}
+	if got := sortNumber(last.number(fixedTime, row)); got != "0.1" {
+		t.Errorf("expected 0.1 for 100ms, got %s", got)
+	}
+}
@@ -0,0 +1,300 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Kinds of channelz entities which can be listed as rows.
+const (
+	KindChannel    = "channel"
+	KindSubchannel = "subchannel"
+	KindServer     = "server"
+	KindSocket     = "socket"
+)
+
+// ParseKind parses the entity kind given on the command line, including its aliases.
+func ParseKind(s string) (string, error) {
+	switch s {
+	case "channel", "channels", "c":
+		return KindChannel, nil
+	case "subchannel", "subchannels", "sc":
+		return KindSubchannel, nil
+	case "server", "servers", "s":
+		return KindServer, nil
+	case "socket", "sockets", "sk":
+		return KindSocket, nil
+	}
+	return "", fmt.Errorf("unknown type %q", s)
+}
+
+// Row is the flattened view of a channelz entity shared by the table renderers.
+// For sockets the call counters hold the stream counters.
+type Row struct {
+	Kind      string
+	ID        int64
+	Name      string
+	State     string
+	Target    string
+	Local     string
+	Remote    string
+	Started   int64
+	Succeeded int64
+	Failed    int64
+	Messages  int64
+	LastCall  *timestamp.Timestamp
+	// Owner is the channel, subchannel or server the row belongs to, if any.
+	Owner  *EntityRef
+	Entity proto.Message
+	// Rate is set when the row was sampled twice.
+	Rate *Rate
+}
+
+// Key identifies the row among the rows of all kinds.
+func (r *Row) Key() string {
+	return fmt.Sprintf("%s/%d", r.Kind, r.ID)
+}
+
+// DisplayName returns the name of the row, falling back to its target or remote address.
+func (r *Row) DisplayName() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Target != "":
+		return r.Target
+	default:
+		return decorateEmpty(r.Remote)
+	}
+}
+
+func channelRow(channel *channelzpb.Channel) *Row {
+	return &Row{
+		Kind:      KindChannel,
+		ID:        channel.Ref.ChannelId,
+		Name:      channel.Ref.Name,
+		State:     channel.Data.GetState().GetState().String(),
+		Target:    channel.Data.Target,
+		Started:   channel.Data.CallsStarted,
+		Succeeded: channel.Data.CallsSucceeded,
+		Failed:    channel.Data.CallsFailed,
+		LastCall:  channel.Data.LastCallStartedTimestamp,
+		Entity:    channel,
+	}
+}
+
+func subchannelRow(subch *channelzpb.Subchannel, owner *EntityRef) *Row {
+	return &Row{
+		Owner:     owner,
+		Kind:      KindSubchannel,
+		ID:        subch.Ref.SubchannelId,
+		Name:      subch.Ref.Name,
+		State:     subch.Data.GetState().GetState().String(),
+		Target:    subch.Data.Target,
+		Started:   subch.Data.CallsStarted,
+		Succeeded: subch.Data.CallsSucceeded,
+		Failed:    subch.Data.CallsFailed,
+		LastCall:  subch.Data.LastCallStartedTimestamp,
+		Entity:    subch,
+	}
+}
+
+func serverRow(server *channelzpb.Server, listenSocket *channelzpb.Socket) *Row {
+	return &Row{
+		Kind:      KindServer,
+		ID:        server.Ref.ServerId,
+		Name:      server.Ref.Name,
+		Local:     addrToString(listenSocket.GetLocal()),
+		Started:   server.Data.CallsStarted,
+		Succeeded: server.Data.CallsSucceeded,
+		Failed:    server.Data.CallsFailed,
+		LastCall:  server.Data.LastCallStartedTimestamp,
+		Entity:    server,
+	}
+}
+
+func socketRow(socket *channelzpb.Socket, owner *EntityRef) *Row {
+	return &Row{
+		Owner:     owner,
+		Kind:      KindSocket,
+		ID:        socket.Ref.SocketId,
+		Name:      socket.Ref.Name,
+		Local:     addrToString(socket.Local),
+		Remote:    addrToString(socket.Remote),
+		Started:   socket.Data.StreamsStarted,
+		Succeeded: socket.Data.StreamsSucceeded,
+		Failed:    socket.Data.StreamsFailed,
+		Messages:  socket.Data.MessagesSent + socket.Data.MessagesReceived,
+		LastCall:  lastSocketActivity(socket.Data),
+		Entity:    socket,
+	}
+}
+
+// visitRows calls fn with a row for every entity of kind, and stops at the first error.
+func (cc *Client) visitRows(ctx context.Context, kind string, fn func(*Row) error) error {
+	switch kind {
+	case KindChannel:
+		return cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
+			return fn(channelRow(channel))
+		})
+	case KindSubchannel:
+		return cc.visitSubchannels(ctx, func(subch *channelzpb.Subchannel, owner *EntityRef) error {
+			return fn(subchannelRow(subch, owner))
+		})
+	case KindServer:
+		return cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
+			// see first socket only
+			var socket *channelzpb.Socket
+			if len(server.ListenSocket) > 0 {
+				var err error
+				if socket, err = cc.findSocketByID(ctx, server.ListenSocket[0].SocketId); err != nil {
+					return err
+				}
+			}
+			return fn(serverRow(server, socket))
+		})
+	case KindSocket:
+		return cc.visitSockets(ctx, func(socket *channelzpb.Socket, owner *EntityRef) error {
+			return fn(socketRow(socket, owner))
+		})
+	}
+	return nil
+}
+
+// visitSubchannels calls fn for every subchannel reachable from the top channels,
+// with the channel or subchannel it belongs to.
+func (cc *Client) visitSubchannels(ctx context.Context, fn func(*channelzpb.Subchannel, *EntityRef) error) error {
+	var visitChannel func(channel *channelzpb.Channel) error
+	var visitSubchannel func(ref *channelzpb.SubchannelRef, owner *EntityRef) error
+	visitChildren := func(refs []*channelzpb.ChannelRef) error {
+		for _, ref := range refs {
+			channel, err := cc.getChannel(ctx, ref.ChannelId)
+			if err != nil {
+				return err
+			}
+			if err := visitChannel(channel); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	seen := make(map[int64]bool)
+	visitSubchannel = func(ref *channelzpb.SubchannelRef, owner *EntityRef) error {
+		if seen[ref.SubchannelId] {
+			return nil
+		}
+		seen[ref.SubchannelId] = true
+
+		res, err := cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: ref.SubchannelId})
+		if err != nil {
+			return err
+		}
+
+		subch := res.Subchannel
+		if err := fn(subch, owner); err != nil {
+			return err
+		}
+
+		self := &EntityRef{Kind: KindSubchannel, ID: subch.Ref.SubchannelId, Name: entityName(subch.Ref.Name, subch.Data)}
+		for _, ref := range subch.SubchannelRef {
+			if err := visitSubchannel(ref, self); err != nil {
+				return err
+			}
+		}
+		return visitChildren(subch.ChannelRef)
+	}
+	visitChannel = func(channel *channelzpb.Channel) error {
+		self := &EntityRef{Kind: KindChannel, ID: channel.Ref.ChannelId, Name: entityName(channel.Ref.Name, channel.Data)}
+		for _, ref := range channel.SubchannelRef {
+			if err := visitSubchannel(ref, self); err != nil {
+				return err
+			}
+		}
+		return visitChildren(channel.ChannelRef)
+	}
+
+	return cc.visitTopChannels(ctx, visitChannel)
+}
+
+// visitSockets calls fn for every socket accepted by the servers and every socket
+// of the channels and subchannels, listen sockets excluded, with the entity it belongs to.
+func (cc *Client) visitSockets(ctx context.Context, fn func(*channelzpb.Socket, *EntityRef) error) error {
+	if err := cc.visitServerSockets(ctx, fn); err != nil {
+		return err
+	}
+
+	visitRefs := func(refs []*channelzpb.SocketRef, owner *EntityRef) error {
+		sockets, err := cc.getSockets(ctx, refs)
+		if err != nil {
+			return err
+		}
+		for _, socket := range sockets {
+			if err := fn(socket, owner); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
+		return visitRefs(channel.SocketRef, &EntityRef{Kind: KindChannel, ID: channel.Ref.ChannelId, Name: entityName(channel.Ref.Name, channel.Data)})
+	})
+	if err != nil {
+		return err
+	}
+	return cc.visitSubchannels(ctx, func(subch *channelzpb.Subchannel, _ *EntityRef) error {
+		return visitRefs(subch.SocketRef, &EntityRef{Kind: KindSubchannel, ID: subch.Ref.SubchannelId, Name: entityName(subch.Ref.Name, subch.Data)})
+	})
+}
+
+// visitServerSockets calls fn for every socket accepted by the servers, with its server.
+func (cc *Client) visitServerSockets(ctx context.Context, fn func(*channelzpb.Socket, *EntityRef) error) error {
+	return cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
+		owner := &EntityRef{Kind: KindServer, ID: server.Ref.ServerId, Name: server.Ref.Name}
+		return cc.visitGetServerSockets(ctx, server.Ref.ServerId, func(socket *channelzpb.Socket) error {
+			return fn(socket, owner)
+		})
+	})
+}
+
+func (cc *Client) getChannel(ctx context.Context, id int64) (*channelzpb.Channel, error) {
+	res, err := cc.cc.GetChannel(ctx, &channelzpb.GetChannelRequest{ChannelId: id})
+	if err != nil {
+		return nil, err
+	}
+	return res.Channel, nil
+}
+
+// Rate is the per second change of the counters of a row between two samples.
+type Rate struct {
+	Calls     float64 `json:"calls"`
+	Succeeded float64 `json:"succeeded"`
+	Failed    float64 `json:"failed"`
+	Messages  float64 `json:"messages"`
+	// ErrorRatio is the failed share of the calls started in the window, -1 without calls.
+	ErrorRatio float64 `json:"error_ratio"`
+}
+
+// rowRate computes the rate of cur since prev, which was sampled elapsed before.
+func rowRate(prev, cur *Row, elapsed time.Duration) Rate {
+	if prev == nil || elapsed <= 0 {
+		return Rate{ErrorRatio: -1}
+	}
+
+	secs := elapsed.Seconds()
+	rate := Rate{
+		Calls:      float64(cur.Started-prev.Started) / secs,
+		Succeeded:  float64(cur.Succeeded-prev.Succeeded) / secs,
+		Failed:     float64(cur.Failed-prev.Failed) / secs,
+		Messages:   float64(cur.Messages-prev.Messages) / secs,
+		ErrorRatio: -1,
+	}
+	if finished := (cur.Succeeded - prev.Succeeded) + (cur.Failed - prev.Failed); finished > 0 {
+		rate.ErrorRatio = float64(cur.Failed-prev.Failed) / float64(finished)
+	}
+	return rate
+}
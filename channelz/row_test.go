@@ -0,0 +1,86 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVisitRows(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient1(&bytes.Buffer{})
+
+	tests := []struct {
+		kind string
+		want int
+	}{
+		{KindChannel, 2},
+		{KindSubchannel, 5},
+		{KindServer, 2},
+		{KindSocket, 8},
+	}
+	for _, tt := range tests {
+		n := 0
+		err := c.visitRows(ctx, tt.kind, func(row *Row) error {
+			if row.Kind != tt.kind {
+				t.Errorf("expected kind %s, got %s", tt.kind, row.Kind)
+			}
+			n++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != tt.want {
+			t.Errorf("%s: expected %d rows, got %d", tt.kind, tt.want, n)
+		}
+	}
+}
+
+// newTestBrokenClient refers to subchannel 99, which the server does not know.
+func newTestBrokenClient(b *bytes.Buffer) *Client {
+	return &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			topChannels: []*channelzpb.Channel{{
+				Ref:           &channelzpb.ChannelRef{ChannelId: 1},
+				Data:          &channelzpb.ChannelData{Target: "lb.test.com"},
+				SubchannelRef: []*channelzpb.SubchannelRef{{SubchannelId: 99}},
+			}},
+		},
+	}
+}
+
+func TestVisitRowsError(t *testing.T) {
+	c := newTestBrokenClient(&bytes.Buffer{})
+
+	err := c.List(&Options{}, context.Background(), KindSubchannel)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestVisitTopChannelsError(t *testing.T) {
+	for _, tc := range []struct {
+		err   error
+		calls int
+	}{
+		{status.Error(codes.Unavailable, "unavailable"), 1},
+		{status.Error(codes.DeadlineExceeded, "timed out"), 1 + maxTopChannelsRetries},
+	} {
+		fake := &fakeChannelzClient{topChannelsErr: tc.err}
+		c := &Client{w: &bytes.Buffer{}, cc: fake}
+
+		err := c.List(&Options{}, context.Background(), KindChannel)
+		if status.Code(err) != status.Code(tc.err) {
+			t.Errorf("expected %v, got %v", tc.err, err)
+		}
+		if fake.topChannelsCalls != tc.calls {
+			t.Errorf("%v: expected %d calls, got %d", tc.err, tc.calls, fake.topChannelsCalls)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package channelz
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Snapshot is every channelz entity of a process at one point in time.
+type Snapshot struct {
+	Time        time.Time
+	Channels    []*channelzpb.Channel
+	Subchannels []*channelzpb.Subchannel
+	Servers     []*channelzpb.Server
+	Sockets     []*channelzpb.Socket
+	// ServerSockets holds the IDs of the sockets accepted by every server, by server ID,
+	// as the servers do not refer to them.
+	ServerSockets map[int64][]int64
+}
+
+// snapshotJSON is the JSON document of a snapshot. The entities are encoded with protojson,
+// as encoding/json can not decode the oneof fields of the generated structs.
+type snapshotJSON struct {
+	Time        time.Time         `json:"time"`
+	Channels    []json.RawMessage `json:"channels"`
+	Subchannels []json.RawMessage `json:"subchannels"`
+	Servers     []json.RawMessage `json:"servers"`
+	Sockets     []json.RawMessage `json:"sockets"`
+
+	ServerSockets map[int64][]int64 `json:"server_sockets,omitempty"`
+}
+
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	v := snapshotJSON{Time: s.Time, ServerSockets: s.ServerSockets}
+	var err error
+	if v.Channels, err = marshalProtos(s.Channels); err != nil {
+		return nil, err
+	}
+	if v.Subchannels, err = marshalProtos(s.Subchannels); err != nil {
+		return nil, err
+	}
+	if v.Servers, err = marshalProtos(s.Servers); err != nil {
+		return nil, err
+	}
+	if v.Sockets, err = marshalProtos(s.Sockets); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	var v snapshotJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*s = Snapshot{Time: v.Time, ServerSockets: v.ServerSockets}
+	for _, raw := range v.Channels {
+		m := &channelzpb.Channel{}
+		if err := protojson.Unmarshal(raw, m); err != nil {
+			return err
+		}
+		s.Channels = append(s.Channels, m)
+	}
+	for _, raw := range v.Subchannels {
+		m := &channelzpb.Subchannel{}
+		if err := protojson.Unmarshal(raw, m); err != nil {
+			return err
+		}
+		s.Subchannels = append(s.Subchannels, m)
+	}
+	for _, raw := range v.Servers {
+		m := &channelzpb.Server{}
+		if err := protojson.Unmarshal(raw, m); err != nil {
+			return err
+		}
+		s.Servers = append(s.Servers, m)
+	}
+	for _, raw := range v.Sockets {
+		m := &channelzpb.Socket{}
+		if err := protojson.Unmarshal(raw, m); err != nil {
+			return err
+		}
+		s.Sockets = append(s.Sockets, m)
+	}
+	return nil
+}
+
+func marshalProtos[M proto.Message](ms []M) ([]json.RawMessage, error) {
+	raws := make([]json.RawMessage, 0, len(ms))
+	for _, m := range ms {
+		raw, err := documentMarshaler.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, raw)
+	}
+	return raws, nil
+}
+
+// TakeSnapshot collects every channel, subchannel, server and socket, each once,
+// including the channels and subchannels nested under subchannels.
+func (cc *Client) TakeSnapshot(ctx context.Context) (*Snapshot, error) {
+	s := &Snapshot{ServerSockets: make(map[int64][]int64)}
+	seenChannels := make(map[int64]bool)
+	seenSubchannels := make(map[int64]bool)
+	seenSockets := make(map[int64]bool)
+
+	addSocket := func(socket *channelzpb.Socket) {
+		if !seenSockets[socket.Ref.SocketId] {
+			seenSockets[socket.Ref.SocketId] = true
+			s.Sockets = append(s.Sockets, socket)
+		}
+	}
+	addSockets := func(refs []*channelzpb.SocketRef) error {
+		var missing []*channelzpb.SocketRef
+		for _, ref := range refs {
+			if !seenSockets[ref.SocketId] {
+				missing = append(missing, ref)
+			}
+		}
+		sockets, err := cc.getSockets(ctx, missing)
+		if err != nil {
+			return err
+		}
+		for _, socket := range sockets {
+			addSocket(socket)
+		}
+		return nil
+	}
+
+	var visitChannel func(channel *channelzpb.Channel) error
+	var visitSubchannel func(id int64) error
+	visitChildren := func(channels []*channelzpb.ChannelRef, subchannels []*channelzpb.SubchannelRef) error {
+		for _, ref := range channels {
+			if seenChannels[ref.ChannelId] {
+				continue
+			}
+			channel, err := cc.getChannel(ctx, ref.ChannelId)
+			if err != nil {
+				return err
+			}
+			if err := visitChannel(channel); err != nil {
+				return err
+			}
+		}
+		for _, ref := range subchannels {
+			if err := visitSubchannel(ref.SubchannelId); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	visitChannel = func(channel *channelzpb.Channel) error {
+		if seenChannels[channel.Ref.ChannelId] {
+			return nil
+		}
+		seenChannels[channel.Ref.ChannelId] = true
+
+		s.Channels = append(s.Channels, channel)
+		if err := addSockets(channel.SocketRef); err != nil {
+			return err
+		}
+		return visitChildren(channel.ChannelRef, channel.SubchannelRef)
+	}
+	visitSubchannel = func(id int64) error {
+		if seenSubchannels[id] {
+			return nil
+		}
+		seenSubchannels[id] = true
+
+		res, err := cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: id})
+		if err != nil {
+			return err
+		}
+		subch := res.Subchannel
+		s.Subchannels = append(s.Subchannels, subch)
+		if err := addSockets(subch.SocketRef); err != nil {
+			return err
+		}
+		return visitChildren(subch.ChannelRef, subch.SubchannelRef)
+	}
+	if err := cc.visitTopChannels(ctx, visitChannel); err != nil {
+		return nil, err
+	}
+
+	err := cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
+		s.Servers = append(s.Servers, server)
+		if err := addSockets(server.ListenSocket); err != nil {
+			return err
+		}
+		return cc.visitGetServerSockets(ctx, server.Ref.ServerId, func(socket *channelzpb.Socket) error {
+			addSocket(socket)
+			s.ServerSockets[server.Ref.ServerId] = append(s.ServerSockets[server.Ref.ServerId], socket.Ref.SocketId)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Time = timeNow()
+	return s, nil
+}
+
+// Snapshot writes a snapshot of every channelz entity, to be compared later with diff.
+func (cc *Client) Snapshot(opts *Options, ctx context.Context) error {
+	s, err := cc.TakeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	return cc.encode(opts, s)
+}
+
+// rows returns the rows of the entities in the snapshot.
+func (s *Snapshot) rows() []*Row {
+	var rows []*Row
+	for _, channel := range s.Channels {
+		rows = append(rows, channelRow(channel))
+	}
+	for _, subch := range s.Subchannels {
+		rows = append(rows, subchannelRow(subch, nil))
+	}
+
+	sockets := make(map[int64]*channelzpb.Socket)
+	for _, socket := range s.Sockets {
+		sockets[socket.Ref.SocketId] = socket
+		rows = append(rows, socketRow(socket, nil))
+	}
+	for _, server := range s.Servers {
+		var listenSocket *channelzpb.Socket
+		if len(server.ListenSocket) > 0 {
+			listenSocket = sockets[server.ListenSocket[0].SocketId]
+		}
+		rows = append(rows, serverRow(server, listenSocket))
+	}
+	return rows
+}
@@ -0,0 +1,155 @@
+package channelz
+
+import (
+	"fmt"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// SocketOption is a socket option with its Additional value unpacked. grpc-go reports
+// SO_RCVTIMEO and SO_SNDTIMEO as timeouts, SO_LINGER as linger and TCP_INFO as TCP info.
+type SocketOption struct {
+	Name    string        `json:"name"`
+	Value   string        `json:"value,omitempty"`
+	Timeout string        `json:"timeout,omitempty"`
+	Linger  *SocketLinger `json:"linger,omitempty"`
+	TCPInfo *TCPInfo      `json:"tcp_info,omitempty"`
+	// Unknown is the type URL of an Additional value which could not be unpacked.
+	Unknown string `json:"unknown,omitempty"`
+}
+
+type SocketLinger struct {
+	Active   bool   `json:"active"`
+	Duration string `json:"duration"`
+}
+
+// TCPInfo is the subset of tcp_info of interest when debugging a connection.
+// The times are in microseconds, as reported by the kernel.
+type TCPInfo struct {
+	State       string `json:"state"`
+	RTT         uint32 `json:"rtt_us"`
+	RTTVar      uint32 `json:"rttvar_us"`
+	RTO         uint32 `json:"rto_us"`
+	SndCwnd     uint32 `json:"snd_cwnd"`
+	SndSsthresh uint32 `json:"snd_ssthresh"`
+	SndMss      uint32 `json:"snd_mss"`
+	RcvMss      uint32 `json:"rcv_mss"`
+	Retransmits uint32 `json:"retransmits"`
+	Retrans     uint32 `json:"retrans"`
+	Unacked     uint32 `json:"unacked"`
+	Sacked      uint32 `json:"sacked"`
+	Lost        uint32 `json:"lost"`
+	Pmtu        uint32 `json:"pmtu"`
+	Reordering  uint32 `json:"reordering"`
+}
+
+// tcpStates are the names of the Linux TCP states, indexed by tcpi_state.
+var tcpStates = []string{"", "ESTABLISHED", "SYN_SENT", "SYN_RECV", "FIN_WAIT1", "FIN_WAIT2",
+	"TIME_WAIT", "CLOSE", "CLOSE_WAIT", "LAST_ACK", "LISTEN", "CLOSING"}
+
+func tcpStateName(state uint32) string {
+	if int(state) < len(tcpStates) && state > 0 {
+		return tcpStates[state]
+	}
+	return fmt.Sprint(state)
+}
+
+func newTCPInfo(info *channelzpb.SocketOptionTcpInfo) *TCPInfo {
+	return &TCPInfo{
+		State:       tcpStateName(info.TcpiState),
+		RTT:         info.TcpiRtt,
+		RTTVar:      info.TcpiRttvar,
+		RTO:         info.TcpiRto,
+		SndCwnd:     info.TcpiSndCwnd,
+		SndSsthresh: info.TcpiSndSsthresh,
+		SndMss:      info.TcpiSndMss,
+		RcvMss:      info.TcpiRcvMss,
+		Retransmits: info.TcpiRetransmits,
+		Retrans:     info.TcpiRetrans,
+		Unacked:     info.TcpiUnacked,
+		Sacked:      info.TcpiSacked,
+		Lost:        info.TcpiLost,
+		Pmtu:        info.TcpiPmtu,
+		Reordering:  info.TcpiReordering,
+	}
+}
+
+// newSocketOption unpacks the Additional value of opt.
+func newSocketOption(opt *channelzpb.SocketOption) *SocketOption {
+	o := &SocketOption{Name: opt.Name, Value: opt.Value}
+	if opt.Additional == nil {
+		return o
+	}
+
+	m, err := anypb.UnmarshalNew(opt.Additional, proto.UnmarshalOptions{})
+	if err != nil {
+		o.Unknown = opt.Additional.TypeUrl
+		return o
+	}
+	switch v := m.(type) {
+	case *channelzpb.SocketOptionTimeout:
+		o.Timeout = v.Duration.AsDuration().String()
+	case *channelzpb.SocketOptionLinger:
+		o.Linger = &SocketLinger{Active: v.Active, Duration: v.Duration.AsDuration().String()}
+	case *channelzpb.SocketOptionTcpInfo:
+		o.TCPInfo = newTCPInfo(v)
+	default:
+		o.Unknown = opt.Additional.TypeUrl
+	}
+	return o
+}
+
+func socketOptions(socket *channelzpb.Socket) []*SocketOption {
+	var opts []*SocketOption
+	for _, opt := range socket.GetData().GetOption() {
+		opts = append(opts, newSocketOption(opt))
+	}
+	return opts
+}
+
+// socketTCPInfo returns the TCP info of the socket, nil when it was not reported.
+func socketTCPInfo(socket *channelzpb.Socket) *TCPInfo {
+	for _, opt := range socketOptions(socket) {
+		if opt.TCPInfo != nil {
+			return opt.TCPInfo
+		}
+	}
+	return nil
+}
+
+func microsString(us uint32) string {
+	return (time.Duration(us) * time.Microsecond).String()
+}
+
+func (cc *Client) describeSocketOption(indent string, opt *SocketOption) {
+	switch {
+	case opt.Timeout != "":
+		cc.printf("%s%s:\t%s\n", indent, opt.Name, opt.Timeout)
+	case opt.Linger != nil:
+		if opt.Linger.Active {
+			cc.printf("%s%s:\tactive, %s\n", indent, opt.Name, opt.Linger.Duration)
+		} else {
+			cc.printf("%s%s:\tinactive\n", indent, opt.Name)
+		}
+	case opt.TCPInfo != nil:
+		info := opt.TCPInfo
+		cc.printf("%s%s:\n", indent, opt.Name)
+		cc.printf("%s  State:      \t%s\n", indent, info.State)
+		cc.printf("%s  RTT:        \t%s\n", indent, microsString(info.RTT))
+		cc.printf("%s  RTTVar:     \t%s\n", indent, microsString(info.RTTVar))
+		cc.printf("%s  RTO:        \t%s\n", indent, microsString(info.RTO))
+		cc.printf("%s  Cwnd:       \t%d\n", indent, info.SndCwnd)
+		cc.printf("%s  Ssthresh:   \t%d\n", indent, info.SndSsthresh)
+		cc.printf("%s  Retransmits:\t%d\n", indent, info.Retransmits)
+		cc.printf("%s  Retrans:    \t%d\n", indent, info.Retrans)
+		cc.printf("%s  Unacked:    \t%d\n", indent, info.Unacked)
+		cc.printf("%s  Lost:       \t%d\n", indent, info.Lost)
+	case opt.Unknown != "":
+		cc.printf("%s%s:\t%s (%s)\n", indent, opt.Name, decorateEmpty(opt.Value), opt.Unknown)
+	default:
+		cc.printf("%s%s:\t%s\n", indent, opt.Name, opt.Value)
+	}
+}
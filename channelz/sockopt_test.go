@@ -0,0 +1,129 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestOptionsClient(t *testing.T, b *bytes.Buffer) *Client {
+	mustAny := func(m *channelzpb.SocketOptionTcpInfo) *anypb.Any {
+		a, err := anypb.New(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	timeout, err := anypb.New(&channelzpb.SocketOptionTimeout{Duration: durationpb.New(5 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	linger, err := anypb.New(&channelzpb.SocketOptionLinger{Active: true, Duration: durationpb.New(10 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	socket := testCreateSocket(socketParam{
+		localIP:    net.IPv4(127, 0, 1, 2),
+		localPort:  9001,
+		remoteIP:   net.IPv4(10, 0, 0, 1),
+		remotePort: 40000,
+	})
+	socket.Ref.SocketId = 200
+	socket.Ref.Name = "opts0"
+	socket.Data.Option = []*channelzpb.SocketOption{
+		{Name: "SO_RCVTIMEO", Additional: timeout},
+		{Name: "SO_LINGER", Additional: linger},
+		{Name: "TCP_INFO", Additional: mustAny(&channelzpb.SocketOptionTcpInfo{
+			TcpiState:       1,
+			TcpiRtt:         1250,
+			TcpiRttvar:      500,
+			TcpiRto:         204000,
+			TcpiSndCwnd:     10,
+			TcpiSndSsthresh: 7,
+			TcpiRetransmits: 1,
+			TcpiRetrans:     2,
+			TcpiUnacked:     3,
+			TcpiLost:        4,
+		})},
+		{Name: "SO_KEEPALIVE", Value: "1"},
+	}
+	return &Client{w: b, cc: &fakeChannelzClient{sockets: []*channelzpb.Socket{socket}}}
+}
+
+func TestDescribeSocketOptions(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestOptionsClient(t, b)
+
+	expected := `
+ID:       	200
+Name:     	opts0
+Local:    	127.0.1.2:9001
+Remote:   	10.0.0.1:40000
+Streams:
+  Started:    	0
+  Succeeded:  	0
+  Failed:     	0
+  LastCreated:	none
+Messages:
+  Sent:    	0
+  Recieved:  	0
+  LastSent:	none
+  LastReceived:	none
+Options:
+  SO_RCVTIMEO:	5s
+  SO_LINGER:	active, 10s
+  TCP_INFO:
+    State:      	ESTABLISHED
+    RTT:        	1.25ms
+    RTTVar:     	500µs
+    RTO:        	204ms
+    Cwnd:       	10
+    Ssthresh:   	7
+    Retransmits:	1
+    Retrans:    	2
+    Unacked:    	3
+    Lost:       	4
+  SO_KEEPALIVE:	1
+Security:
+`
+	if err := c.DescribeServerSocket(&Options{}, context.Background(), "200"); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestDescribeSocketOptionsJSON(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestOptionsClient(t, b)
+
+	if err := c.DescribeServerSocket(&Options{Json: true}, context.Background(), "200"); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Options []*SocketOption `json:"options"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Options) != 4 {
+		t.Fatalf("expected 4 options, got %d", len(doc.Options))
+	}
+	if doc.Options[0].Timeout != "5s" {
+		t.Errorf("unexpected timeout %q", doc.Options[0].Timeout)
+	}
+	if l := doc.Options[1].Linger; l == nil || !l.Active || l.Duration != "10s" {
+		t.Errorf("unexpected linger %+v", l)
+	}
+	if info := doc.Options[2].TCPInfo; info == nil || info.State != "ESTABLISHED" || info.RTT != 1250 || info.Lost != 4 {
+		t.Errorf("unexpected tcp info %+v", info)
+	}
+}
@@ -0,0 +1,145 @@
+package channelz
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Output formats of the -o flag.
+const (
+	FormatTable    = ""
+	FormatWide     = "wide"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatYAML     = "yaml"
+)
+
+// tableWriter renders the header and the rows of a table.
+type tableWriter interface {
+	Header(cells []string)
+	Row(cells []string)
+	Flush() error
+}
+
+// newTableWriter returns the renderer of format, which must be a table format.
+func newTableWriter(w io.Writer, format string) (tableWriter, error) {
+	switch format {
+	case FormatTable, FormatWide:
+		return &alignedTable{w: w}, nil
+	case FormatTSV:
+		return &tsvTable{w: w}, nil
+	case FormatCSV:
+		return &csvTable{w: csv.NewWriter(w)}, nil
+	case FormatMarkdown, "md":
+		return &markdownTable{w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// alignedTable pads the columns to the width of their widest cell, two spaces apart. The
+// width of a cell is the width of its text without colors.
+type alignedTable struct {
+	w    io.Writer
+	rows [][]string
+}
+
+func (t *alignedTable) Header(cells []string) { t.Row(cells) }
+
+func (t *alignedTable) Row(cells []string) { t.rows = append(t.rows, cells) }
+
+func (t *alignedTable) Flush() error {
+	var widths []int
+	for _, row := range t.rows {
+		for i, c := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := visibleWidth(c); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	for _, row := range t.rows {
+		var b strings.Builder
+		for i, c := range row {
+			b.WriteString(c)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(c)+2))
+			}
+		}
+		b.WriteString("\n")
+		if _, err := io.WriteString(t.w, b.String()); err != nil {
+			return err
+		}
+	}
+	t.rows = nil
+	return nil
+}
+
+// visibleWidth is the width of s on a terminal, without its colors.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, ""))
+}
+
+type tsvTable struct {
+	w io.Writer
+}
+
+var tsvEscaper = strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func (t *tsvTable) Header(cells []string) { t.Row(cells) }
+
+func (t *tsvTable) Row(cells []string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = tsvEscaper.Replace(c)
+	}
+	_, _ = fmt.Fprintln(t.w, strings.Join(escaped, "\t"))
+}
+
+func (t *tsvTable) Flush() error { return nil }
+
+type csvTable struct {
+	w *csv.Writer
+}
+
+func (t *csvTable) Header(cells []string) { t.Row(cells) }
+
+func (t *csvTable) Row(cells []string) { _ = t.w.Write(cells) }
+
+func (t *csvTable) Flush() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+type markdownTable struct {
+	w io.Writer
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", "<br>")
+
+func (t *markdownTable) Header(cells []string) {
+	t.Row(cells)
+	sep := make([]string, len(cells))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	t.Row(sep)
+}
+
+func (t *markdownTable) Row(cells []string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = markdownEscaper.Replace(c)
+	}
+	_, _ = fmt.Fprintf(t.w, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+func (t *markdownTable) Flush() error { return nil }
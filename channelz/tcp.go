@@ -0,0 +1,102 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// TCPRow is a socket with its TCP info.
+type TCPRow struct {
+	Socket *EntityRef `json:"socket"`
+	Owner  *EntityRef `json:"owner,omitempty"`
+	Local  string     `json:"local"`
+	Remote string     `json:"remote"`
+	*TCPInfo
+}
+
+// tcpSorts are the keys the TCP rows can be ranked by, worst first.
+var tcpSorts = map[string]func(a, b *TCPInfo) bool{
+	"retransmits": func(a, b *TCPInfo) bool {
+		if a.Retransmits != b.Retransmits {
+			return a.Retransmits > b.Retransmits
+		}
+		return a.Retrans > b.Retrans
+	},
+	"rtt":     func(a, b *TCPInfo) bool { return a.RTT > b.RTT },
+	"lost":    func(a, b *TCPInfo) bool { return a.Lost > b.Lost },
+	"unacked": func(a, b *TCPInfo) bool { return a.Unacked > b.Unacked },
+}
+
+// TCPSortKeys returns the keys accepted by --sort-by of tcp.
+func TCPSortKeys() []string {
+	keys := make([]string, 0, len(tcpSorts))
+	for k := range tcpSorts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TCPSockets ranks the client and server sockets which report TCP_INFO by opts.SortBy,
+// retransmits by default, and prints the opts.Top worst ones, all of them when zero.
+func (cc *Client) TCPSockets(opts *Options, ctx context.Context) error {
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = "retransmits"
+	}
+	less, ok := tcpSorts[sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort key %q, expected one of %v", sortBy, TCPSortKeys())
+	}
+
+	var rows []*TCPRow
+	sockets := 0
+	err := cc.visitSockets(ctx, func(socket *channelzpb.Socket, owner *EntityRef) error {
+		sockets++
+		info := socketTCPInfo(socket)
+		if info == nil {
+			return nil
+		}
+		rows = append(rows, &TCPRow{
+			Socket:  &EntityRef{Kind: KindSocket, ID: socket.Ref.SocketId, Name: socket.Ref.Name},
+			Owner:   owner,
+			Local:   addrToString(socket.Local),
+			Remote:  addrToString(socket.Remote),
+			TCPInfo: info,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 && sockets > 0 {
+		opts.warnf("none of the %d sockets reported TCP_INFO", sockets)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i].TCPInfo, rows[j].TCPInfo) })
+	if opts.Top > 0 && len(rows) > opts.Top {
+		rows = rows[:opts.Top]
+	}
+
+	if opts.document() {
+		if rows == nil {
+			rows = []*TCPRow{}
+		}
+		return cc.encode(opts, rows)
+	}
+
+	cc.printf("ID\tOwner\tRemote\tState\tRTT\tRTTVar\tCwnd\tRetransmits\tRetrans\tLost\tUnacked\n")
+	for _, row := range rows {
+		owner := "-"
+		if row.Owner != nil {
+			owner = row.Owner.String()
+		}
+		cc.printf("%d\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n", row.Socket.ID, owner, decorateEmpty(row.Remote),
+			row.State, microsString(row.RTT), microsString(row.RTTVar), row.SndCwnd,
+			row.Retransmits, row.Retrans, row.Lost, row.Unacked)
+	}
+	return nil
+}
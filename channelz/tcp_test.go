@@ -0,0 +1,83 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newTCPSocket(t *testing.T, id int64, remote byte, info *channelzpb.SocketOptionTcpInfo) *channelzpb.Socket {
+	socket := testCreateSocket(socketParam{
+		localIP:    net.IPv4(127, 0, 0, 1),
+		localPort:  9001,
+		remoteIP:   net.IPv4(10, 0, 0, remote),
+		remotePort: 40000,
+	})
+	socket.Ref.SocketId = id
+	a, err := anypb.New(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket.Data.Option = []*channelzpb.SocketOption{{Name: "TCP_INFO", Additional: a}}
+	return socket
+}
+
+func newTestTCPClient(t *testing.T, b *bytes.Buffer) *Client {
+	sock1 := newTCPSocket(t, 11, 1, &channelzpb.SocketOptionTcpInfo{TcpiState: 1, TcpiRtt: 900, TcpiRttvar: 100, TcpiSndCwnd: 10, TcpiRetransmits: 0, TcpiLost: 0})
+	sock2 := newTCPSocket(t, 12, 2, &channelzpb.SocketOptionTcpInfo{TcpiState: 1, TcpiRtt: 80000, TcpiRttvar: 20000, TcpiSndCwnd: 2, TcpiRetransmits: 3, TcpiRetrans: 1, TcpiLost: 5})
+	sock3 := newTCPSocket(t, 13, 3, &channelzpb.SocketOptionTcpInfo{TcpiState: 1, TcpiRtt: 150000, TcpiRttvar: 1000, TcpiSndCwnd: 10, TcpiRetransmits: 1, TcpiLost: 1, TcpiUnacked: 4})
+
+	server := &channelzpb.Server{Ref: &channelzpb.ServerRef{ServerId: 1, Name: "srv"}, Data: &channelzpb.ServerData{}}
+	channel := &channelzpb.Channel{
+		Ref:       &channelzpb.ChannelRef{ChannelId: 2, Name: "backend"},
+		Data:      &channelzpb.ChannelData{},
+		SocketRef: []*channelzpb.SocketRef{sock3.Ref},
+	}
+	return &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			topChannels:   []*channelzpb.Channel{channel},
+			servers:       []*channelzpb.Server{server},
+			sockets:       []*channelzpb.Socket{sock1, sock2, sock3},
+			serverSockets: map[int64][]*channelzpb.SocketRef{1: {sock1.Ref, sock2.Ref}},
+		},
+	}
+}
+
+func TestTCPSockets(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestTCPClient(t, b)
+
+	expected := `
+ID	Owner	Remote	State	RTT	RTTVar	Cwnd	Retransmits	Retrans	Lost	Unacked
+12	server 1 (srv)	10.0.0.2:40000	ESTABLISHED	80ms	20ms	2	3	1	5	0
+13	channel 2 (backend)	10.0.0.3:40000	ESTABLISHED	150ms	1ms	10	1	0	1	4
+11	server 1 (srv)	10.0.0.1:40000	ESTABLISHED	900µs	100µs	10	0	0	0	0
+`
+	if err := c.TCPSockets(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestTCPSocketsSortByRTT(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestTCPClient(t, b)
+
+	expected := `
+ID	Owner	Remote	State	RTT	RTTVar	Cwnd	Retransmits	Retrans	Lost	Unacked
+13	channel 2 (backend)	10.0.0.3:40000	ESTABLISHED	150ms	1ms	10	1	0	1	4
+`
+	if err := c.TCPSockets(&Options{SortBy: "rtt", Top: 1}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+
+	if err := c.TCPSockets(&Options{SortBy: "jitter"}, context.Background()); err == nil {
+		t.Error("expected an error for an unknown sort key")
+	}
+}
@@ -0,0 +1,222 @@
+package channelz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Output formats of the -o flag which take an argument, as in -o custom-columns=ID:.ref.channelId.
+const (
+	FormatGoTemplate     = "go-template"
+	FormatGoTemplateFile = "go-template-file"
+	FormatCustomColumns  = "custom-columns"
+)
+
+// rowPrinter prints the list rows with a Go template or as custom columns.
+type rowPrinter struct {
+	w   io.Writer
+	now time.Time
+	// tmpl is executed once with the views of all the rows.
+	tmpl    *template.Template
+	columns []customColumn
+}
+
+// newRowPrinter returns the printer of format, nil when format is not a template
+// or a custom-columns one.
+func newRowPrinter(w io.Writer, format string) (*rowPrinter, error) {
+	name, arg, ok := strings.Cut(format, "=")
+	p := &rowPrinter{w: w}
+	switch name {
+	case FormatGoTemplate, FormatGoTemplateFile:
+		if !ok || arg == "" {
+			return nil, fmt.Errorf("-o %s requires a template, as in -o %s=...", name, name)
+		}
+		text := arg
+		if name == FormatGoTemplateFile {
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			text = string(data)
+		}
+
+		var err error
+		if p.tmpl, err = template.New("output").Funcs(p.templateFuncs()).Parse(text); err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		return p, nil
+	case FormatCustomColumns:
+		columns, err := parseCustomColumns(arg)
+		if err != nil {
+			return nil, err
+		}
+		p.columns = columns
+		return p, nil
+	}
+	return nil, nil
+}
+
+// templateFuncs are the helpers of the templates, in addition to the builtin ones:
+//
+//	elapsed TIMESTAMP   the time since the timestamp, as in the LastCall column
+//	addr ADDRESS        the address formatted as host:port, unix:PATH or its name
+//	failureRatio ENTITY the failed share of the finished calls, or streams, of the entity
+func (p *rowPrinter) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"elapsed": func(ts *timestamp.Timestamp) string { return elapsedTimestamp(p.now, ts) },
+		"addr":    addrToString,
+		"failureRatio": func(entity interface{}) (float64, error) {
+			row, err := entityRow(entity)
+			if err != nil {
+				return 0, err
+			}
+			return failureRatio(row), nil
+		},
+	}
+}
+
+// entityRow returns the row of a channel, subchannel, server or socket.
+func entityRow(entity interface{}) (*Row, error) {
+	switch e := entity.(type) {
+	case *channelzpb.Channel:
+		return channelRow(e), nil
+	case *channelzpb.Subchannel:
+		return subchannelRow(e, nil), nil
+	case *channelzpb.Server:
+		return serverRow(e, nil), nil
+	case *channelzpb.Socket:
+		return socketRow(e, nil), nil
+	case *SocketView:
+		return socketRow(e.Socket, nil), nil
+	}
+	return nil, fmt.Errorf("%T is not a channelz entity", entity)
+}
+
+// rowView is the structured view of the row the templates are rendered from.
+func rowView(row *Row) proto.Message {
+	if socket, ok := row.Entity.(*channelzpb.Socket); ok {
+		return newSocketView(socket)
+	}
+	return row.Entity
+}
+
+// rateDocument is the document of a row sampled twice, with its rates next to the fields of the entity.
+type rateDocument struct {
+	proto.Message
+	Rate *Rate `json:"rate"`
+}
+
+// rowDocument is the view of the row the documents, the queries and the custom columns are rendered from.
+func rowDocument(row *Row) interface{} {
+	if row.Rate == nil {
+		return rowView(row)
+	}
+	return &rateDocument{Message: rowView(row), Rate: row.Rate}
+}
+
+// Print prints the rows, at now.
+func (p *rowPrinter) Print(now time.Time, rows []*Row) error {
+	p.now = now
+	if p.tmpl != nil {
+		views := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			views = append(views, rowView(row))
+		}
+		return p.tmpl.Execute(p.w, views)
+	}
+
+	table, _ := newTableWriter(p.w, FormatTable)
+	var names []string
+	for _, col := range p.columns {
+		names = append(names, col.name)
+	}
+	table.Header(names)
+	for _, row := range rows {
+		doc, err := queryEncoder.generic(rowDocument(row))
+		if err != nil {
+			return err
+		}
+		var cells []string
+		for _, col := range p.columns {
+			cell, err := col.value(doc)
+			if err != nil {
+				return err
+			}
+			cells = append(cells, cell)
+		}
+		table.Row(cells)
+	}
+	return table.Flush()
+}
+
+// customColumn is a NAME:PATH column of -o custom-columns, where PATH is a JSONPath as in
+// -o jsonpath, with or without its braces: .ref.channelId or {.ref.channelId}.
+type customColumn struct {
+	name string
+	path jsonPath
+}
+
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("-o %s requires columns, as in -o %s=ID:.ref.channelId", FormatCustomColumns, FormatCustomColumns)
+	}
+
+	var columns []customColumn
+	for _, c := range strings.Split(spec, ",") {
+		name, path, ok := strings.Cut(c, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid custom column %q, expected NAME:PATH", c)
+		}
+		if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+			path = path[1 : len(path)-1]
+		}
+		compiled, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom column %q: %v", c, err)
+		}
+		columns = append(columns, customColumn{name: name, path: compiled})
+	}
+	return columns, nil
+}
+
+// value returns the values of the path of the column in doc joined with commas,
+// or <none> when there is none.
+func (c customColumn) value(doc interface{}) (string, error) {
+	values, err := c.path.eval(doc, doc)
+	if err != nil {
+		return "", err
+	}
+
+	var cells []string
+	for _, v := range values {
+		if s := formatDocumentValue(v); s != "" {
+			cells = append(cells, s)
+		}
+	}
+	return decorateEmpty(strings.Join(cells, ",")), nil
+}
+
+func formatDocumentValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,86 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListGoTemplate(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	format := `go-template={{range .}}{{.Ref.ChannelId}} {{.Data.Target}} {{elapsed .Data.LastCallStartedTimestamp}} {{printf "%.2f" (failureRatio .)}}{{"\n"}}{{end}}`
+	if err := c.List(&Options{Format: format}, context.Background(), KindChannel); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+0 foo0.test.com 0ms 0.10
+1 foo1.test.com 0ms 0.10
+`, b.String())
+}
+
+func TestListGoTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remotes.tmpl")
+	if err := os.WriteFile(path, []byte(`{{range .}}{{.Ref.SocketId}} {{addr .Remote}}{{"\n"}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	if err := c.List(&Options{Format: "go-template-file=" + path, SortBy: "id"}, context.Background(), KindServerSocket); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+7 10.0.0.1:40000
+8 10.0.0.2:40001
+9 10.0.0.3:40002
+`, b.String())
+}
+
+func TestListCustomColumns(t *testing.T) {
+	for _, tc := range []struct {
+		kind     string
+		format   string
+		expected string
+	}{
+		{KindChannel, "custom-columns=ID:.ref.channelId,TARGET:.data.target,STATE:.data.state.state,SUBCHANNELS:{.subchannel_ref[*].subchannel_id}", `
+ID  TARGET         STATE  SUBCHANNELS
+0   foo0.test.com  READY  0
+1   foo1.test.com  READY  1,2,3,4
+`},
+		{KindServer, "custom-columns=NAME:.ref.name,LISTEN:.listenSocket[0].name,MISSING:.data.nothing", `
+NAME     LISTEN  MISSING
+server0  sock0   <none>
+server1  sock1   <none>
+`},
+	} {
+		t.Run(tc.kind, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			c := newTestClient1(b)
+			if err := c.List(&Options{Format: tc.format}, context.Background(), tc.kind); err != nil {
+				t.Fatal(err)
+			}
+			assertOutput(t, tc.expected, b.String())
+		})
+	}
+}
+
+func TestListOutputFormatErrors(t *testing.T) {
+	for _, format := range []string{
+		"go-template",
+		"go-template={{.Foo",
+		"go-template-file=/nonexistent/template",
+		"custom-columns=",
+		"custom-columns=ID",
+		"custom-columns=ID:.ref[x]",
+	} {
+		c := newTestClient1(&bytes.Buffer{})
+		if err := c.List(&Options{Format: format}, context.Background(), KindChannel); err == nil {
+			t.Errorf("expected an error for -o %s", format)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package channelz
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
 )
 
 func stringTimestamp(ts *timestamp.Timestamp) string {
@@ -22,16 +25,25 @@ func stringTimestamp(ts *timestamp.Timestamp) string {
 }
 
 func elapsedTimestamp(now time.Time, ts *timestamp.Timestamp) string {
-	if ts != nil && ts.Seconds == 0 && ts.Nanos == 0 {
+	d, ok := sinceTimestamp(now, ts)
+	if !ok {
 		return "none"
 	}
+	return prettyDuration(d)
+}
+
+// sinceTimestamp returns the time between ts and now, false when ts is not set.
+func sinceTimestamp(now time.Time, ts *timestamp.Timestamp) (time.Duration, bool) {
+	if ts != nil && ts.Seconds == 0 && ts.Nanos == 0 {
+		return 0, false
+	}
 
 	pt, err := ptypes.Timestamp(ts)
 	if err != nil {
-		return "none"
+		return 0, false
 	}
 
-	return prettyDuration(now.Sub(pt))
+	return now.Sub(pt), true
 }
 
 func prettyDuration(d time.Duration) string {
@@ -56,3 +68,48 @@ func prettyDuration(d time.Duration) string {
 		return fmt.Sprintf("%dms", v)
 	}
 }
+
+// lastSocketActivity returns the latest stream or message timestamp of a socket.
+func lastSocketActivity(data *channelzpb.SocketData) *timestamp.Timestamp {
+	return latestTimestamp(
+		data.GetLastLocalStreamCreatedTimestamp(),
+		data.GetLastRemoteStreamCreatedTimestamp(),
+		data.GetLastMessageSentTimestamp(),
+		data.GetLastMessageReceivedTimestamp(),
+	)
+}
+
+// latestTimestamp returns the latest of the set timestamps, or nil when none is set.
+func latestTimestamp(ts ...*timestamp.Timestamp) *timestamp.Timestamp {
+	var last *timestamp.Timestamp
+	for _, t := range ts {
+		if t == nil || (t.Seconds == 0 && t.Nanos == 0) {
+			continue
+		}
+		if last == nil || t.AsTime().After(last.AsTime()) {
+			last = t
+		}
+	}
+	return last
+}
+
+// ParseDuration parses a duration like time.ParseDuration, with the additional day unit "d",
+// as in "30d" or "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	if i := strings.Index(s, "d"); i > 0 {
+		days, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d := time.Duration(days * float64(24*time.Hour))
+		if rest := s[i+1:]; rest != "" {
+			r, err := time.ParseDuration(rest)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			d += r
+		}
+		return d, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,456 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// Screen is the terminal top draws on, already switched to raw mode by the caller.
+type Screen struct {
+	In io.Reader
+	// Size returns the current width and height of the terminal.
+	Size func() (width, height int)
+}
+
+// keys read from the terminal, besides the printable ones.
+const (
+	keyUp = iota + utf8.MaxRune + 1
+	keyDown
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyTab
+	keyCtrlC
+)
+
+var topKinds = []string{KindChannel, KindSubchannel, KindServer, KindSocket}
+
+// topRow is a row with its rate since the previous sample.
+type topRow struct {
+	*Row
+	rate Rate
+}
+
+// failureRatio returns the failed share of the calls in the last sample window.
+func (r *topRow) failureRatio() float64 {
+	if r.rate.Calls <= 0 {
+		return 0
+	}
+	return r.rate.Failed / r.rate.Calls
+}
+
+var topSorts = []struct {
+	name string
+	less func(a, b *topRow) bool
+}{
+	{"calls/s", func(a, b *topRow) bool { return a.rate.Calls > b.rate.Calls }},
+	{"fail/s", func(a, b *topRow) bool { return a.rate.Failed > b.rate.Failed }},
+	{"fail%", func(a, b *topRow) bool { return a.failureRatio() > b.failureRatio() }},
+	{"id", func(a, b *topRow) bool { return a.ID < b.ID }},
+	{"name", func(a, b *topRow) bool { return a.DisplayName() < b.DisplayName() }},
+}
+
+// topSample is the last sample of a kind, kept to compute the rates of the next one.
+type topSample struct {
+	rows map[string]*topRow
+	at   time.Time
+}
+
+type topView struct {
+	tab       int
+	sort      int
+	reverse   bool
+	filter    string
+	editing   bool
+	paused    bool
+	cursor    int
+	rows      []*topRow
+	samples   map[string]*topSample
+	detail    *Row
+	detailOut []string
+	scroll    int
+	// err is the error of the last refresh, shown in the status line until a refresh succeeds.
+	err error
+}
+
+// Top runs a full screen view of the channels, subchannels, servers and sockets, sorted by their
+// live rates and refreshed every opts.Interval, until ctx is done or the user quits.
+func (cc *Client) Top(opts *Options, ctx context.Context, screen *Screen) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	keys := make(chan rune)
+	go readKeys(screen.In, keys)
+
+	// with nothing to show yet, the errors of the first refresh end top.
+	v := &topView{samples: make(map[string]*topSample)}
+	if err := cc.refreshTop(ctx, v); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer cc.printf("\033[H\033[2J")
+
+	for {
+		cc.drawTop(opts, v, screen)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if !v.paused {
+				v.err = cc.refreshTop(ctx, v)
+			}
+		case key, ok := <-keys:
+			if !ok || v.handleKey(key) {
+				return nil
+			}
+			switch {
+			case v.detail != nil && v.detailOut == nil:
+				v.err = cc.describeTop(ctx, v)
+			case v.detail == nil && v.samples[topKinds[v.tab]] == nil:
+				v.err = cc.refreshTop(ctx, v)
+			case v.detail == nil:
+				v.applyView()
+			}
+		}
+	}
+}
+
+// readKeys decodes the keys read from in, and closes keys at the end of in.
+func readKeys(in io.Reader, keys chan<- rune) {
+	defer close(keys)
+
+	buf := make([]byte, 32)
+	for {
+		n, err := in.Read(buf)
+		for b := buf[:n]; len(b) > 0; {
+			var key rune
+			switch {
+			case bytes.HasPrefix(b, []byte("\x1b[A")):
+				key, b = keyUp, b[3:]
+			case bytes.HasPrefix(b, []byte("\x1b[B")):
+				key, b = keyDown, b[3:]
+			case bytes.HasPrefix(b, []byte("\x1b[")):
+				// ignore the other escape sequences
+				b = b[len(b):]
+				continue
+			default:
+				r, size := utf8.DecodeRune(b)
+				b = b[size:]
+				switch r {
+				case '\r', '\n':
+					key = keyEnter
+				case 0x1b:
+					key = keyEsc
+				case 0x7f, '\b':
+					key = keyBackspace
+				case '\t':
+					key = keyTab
+				case 0x03:
+					key = keyCtrlC
+				default:
+					key = r
+				}
+			}
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleKey updates the view for key, and reports whether to quit.
+func (v *topView) handleKey(key rune) (quit bool) {
+	if key == keyCtrlC {
+		return true
+	}
+
+	if v.editing {
+		switch key {
+		case keyEnter:
+			v.editing = false
+		case keyEsc:
+			v.editing, v.filter = false, ""
+		case keyBackspace:
+			if len(v.filter) > 0 {
+				_, size := utf8.DecodeLastRuneInString(v.filter)
+				v.filter = v.filter[:len(v.filter)-size]
+			}
+		default:
+			if key <= utf8.MaxRune {
+				v.filter += string(key)
+			}
+		}
+		v.cursor = 0
+		return false
+	}
+
+	if v.detail != nil {
+		switch key {
+		case 'q', keyEsc, keyBackspace, keyEnter:
+			v.detail, v.detailOut, v.scroll = nil, nil, 0
+		case keyUp, 'k':
+			if v.scroll > 0 {
+				v.scroll--
+			}
+		case keyDown, 'j':
+			v.scroll++
+		case 'p':
+			v.paused = !v.paused
+		}
+		return false
+	}
+
+	switch key {
+	case 'q':
+		return true
+	case '1', '2', '3', '4':
+		v.tab, v.cursor = int(key-'1'), 0
+	case keyTab:
+		v.tab, v.cursor = (v.tab+1)%len(topKinds), 0
+	case 's':
+		v.sort = (v.sort + 1) % len(topSorts)
+	case 'r':
+		v.reverse = !v.reverse
+	case '/':
+		v.editing = true
+	case 'p':
+		v.paused = !v.paused
+	case keyUp, 'k':
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case keyDown, 'j':
+		if v.cursor < len(v.rows)-1 {
+			v.cursor++
+		}
+	case keyEnter:
+		if v.cursor < len(v.rows) {
+			v.detail = v.rows[v.cursor].Row
+		}
+	}
+	return false
+}
+
+// refreshTop samples the rows of the current tab, or the entity of the detail view.
+// On an error the previous sample is kept.
+func (cc *Client) refreshTop(ctx context.Context, v *topView) error {
+	if v.detail != nil {
+		return cc.describeTop(ctx, v)
+	}
+
+	kind := topKinds[v.tab]
+	cur := &topSample{rows: make(map[string]*topRow)}
+	err := cc.visitRows(ctx, kind, func(row *Row) error {
+		cur.rows[row.Key()] = &topRow{Row: row}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cur.at = timeNow()
+
+	if prev := v.samples[kind]; prev != nil {
+		for key, row := range cur.rows {
+			if last := prev.rows[key]; last != nil {
+				row.rate = rowRate(last.Row, row.Row, cur.at.Sub(prev.at))
+			}
+		}
+	}
+	v.samples[kind] = cur
+	v.applyView()
+	return nil
+}
+
+// applyView filters and sorts the rows of the last sample of the current tab.
+func (v *topView) applyView() {
+	v.rows = v.rows[:0]
+	if sample := v.samples[topKinds[v.tab]]; sample != nil {
+		for _, row := range sample.rows {
+			if v.filter == "" || strings.Contains(row.DisplayName()+" "+row.Target+" "+row.Remote, v.filter) {
+				v.rows = append(v.rows, row)
+			}
+		}
+	}
+
+	less := topSorts[v.sort].less
+	sort.SliceStable(v.rows, func(i, j int) bool {
+		a, b := v.rows[i], v.rows[j]
+		if v.reverse {
+			a, b = b, a
+		}
+		if less(a, b) != less(b, a) {
+			return less(a, b)
+		}
+		return a.ID < b.ID
+	})
+
+	if v.cursor >= len(v.rows) {
+		v.cursor = len(v.rows) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+// describeTop renders the describe view and the trace of the detail entity.
+func (cc *Client) describeTop(ctx context.Context, v *topView) error {
+	b := &bytes.Buffer{}
+	out := &Client{cc: cc.cc, w: b}
+	// the detail pane is clipped to the screen by the length of its lines, so it is not colored.
+	var plain palette
+	w := newTraceWalker(cc)
+
+	switch entity := v.detail.Entity.(type) {
+	case *channelzpb.Channel:
+		channel, err := cc.getChannel(ctx, entity.Ref.ChannelId)
+		if err != nil {
+			return err
+		}
+		if err := out.describeChannel(ctx, plain, channel); err != nil {
+			return err
+		}
+		if err := w.walkChannel(ctx, channel); err != nil {
+			return err
+		}
+	case *channelzpb.Subchannel:
+		res, err := cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: entity.Ref.SubchannelId})
+		if err != nil {
+			return err
+		}
+		out.describeSubchannel(plain, res.Subchannel)
+		if err := w.walkSubchannel(ctx, entity.Ref.SubchannelId); err != nil {
+			return err
+		}
+	case *channelzpb.Server:
+		server, err := cc.findServerByID(ctx, entity.Ref.ServerId)
+		if err != nil {
+			return err
+		}
+		if server != nil {
+			out.describeServer(plain, server)
+		}
+	case *channelzpb.Socket:
+		socket, err := cc.findSocketByID(ctx, entity.Ref.SocketId)
+		if err != nil {
+			return err
+		}
+		if socket != nil {
+			out.describeSocket(plain, socket)
+		}
+	}
+
+	if events := w.sortedEvents(); len(events) != 0 {
+		out.printf("\nTrace events:\n")
+		out.printEventsHeader()
+		for _, ev := range events {
+			out.printTraceEvent(plain, ev)
+		}
+	}
+
+	v.detailOut = strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	return nil
+}
+
+func (cc *Client) drawTop(opts *Options, v *topView, screen *Screen) {
+	width, height := screen.Size()
+	var lines []string
+
+	title := fmt.Sprintf("channelzcli top - %s - %s", opts.Address, timeNow().Format("15:04:05"))
+	if v.paused {
+		title += " [paused]"
+	}
+	lines = append(lines, title)
+
+	var tabs []string
+	for i, kind := range topKinds {
+		tab := fmt.Sprintf(" %d %ss ", i+1, kind)
+		if i == v.tab {
+			tab = "\033[7m" + tab + "\033[0m"
+		}
+		tabs = append(tabs, tab)
+	}
+	lines = append(lines, strings.Join(tabs, " "))
+
+	status := fmt.Sprintf("sort: %s", topSorts[v.sort].name)
+	if v.reverse {
+		status += " (reversed)"
+	}
+	status += "  filter: " + v.filter
+	if v.editing {
+		status += "_"
+	}
+	if v.err != nil {
+		status += "  error: " + v.err.Error()
+	}
+	lines = append(lines, status, "")
+
+	body := height - len(lines) - 1
+	if v.detail != nil {
+		if v.scroll > len(v.detailOut)-1 {
+			v.scroll = len(v.detailOut) - 1
+		}
+		for i := v.scroll; i < len(v.detailOut) && i-v.scroll < body; i++ {
+			lines = append(lines, strings.ReplaceAll(v.detailOut[i], "\t", "  "))
+		}
+		lines = padLines(lines, height-1)
+		lines = append(lines, "up/down scroll, p pause, esc back")
+	} else {
+		lines = append(lines, fmt.Sprintf("%-8s %-40s %-17s %9s %9s %9s %7s %8s",
+			"ID", "Name", "State", "Calls", "Calls/s", "Fail/s", "Fail%", "LastCall"))
+		now := timeNow()
+
+		// keep the cursor on the screen
+		first := 0
+		if v.cursor >= body-1 {
+			first = v.cursor - body + 2
+		}
+		for i := first; i < len(v.rows) && i-first < body-1; i++ {
+			row := v.rows[i]
+			line := fmt.Sprintf("%-8d %-40s %-17s %9d %9.1f %9.1f %6.1f%% %8s",
+				row.ID, truncate(row.DisplayName(), 40), decorateEmpty(row.State),
+				row.Started, row.rate.Calls, row.rate.Failed, 100*row.failureRatio(),
+				elapsedTimestamp(now, row.LastCall))
+			if i == v.cursor {
+				line = "\033[7m" + truncate(line, width) + "\033[0m"
+			}
+			lines = append(lines, line)
+		}
+		lines = padLines(lines, height-1)
+		lines = append(lines, "1-4/tab switch, s sort, r reverse, / filter, enter describe, p pause, q quit")
+	}
+
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "\033[") {
+			lines[i] = truncate(line, width)
+		}
+	}
+	cc.printf("\033[H\033[2J%s", strings.Join(lines, "\r\n"))
+}
+
+func padLines(lines []string, n int) []string {
+	for len(lines) < n {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
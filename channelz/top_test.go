@@ -0,0 +1,87 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTop(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestClient1(b)
+	screen := &Screen{
+		Size: func() (int, int) { return 120, 30 },
+	}
+
+	t.Run("SortByName", func(t *testing.T) {
+		b.Reset()
+		// subchannels tab, sort by name reversed, then quit
+		screen.In = strings.NewReader("2ssssrq")
+		if err := c.Top(&Options{}, context.Background(), screen); err != nil {
+			t.Fatal(err)
+		}
+
+		frames := strings.Split(b.String(), "\033[H\033[2J")
+		last := frames[len(frames)-2]
+		if !strings.Contains(last, "sort: name (reversed)") {
+			t.Errorf("expected sort by name reversed, got:\n%s", last)
+		}
+		if i, j := strings.Index(last, "bar4"), strings.Index(last, "bar0"); i < 0 || j < 0 || i > j {
+			t.Errorf("expected bar4 before bar0, got:\n%s", last)
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		b.Reset()
+		screen.In = strings.NewReader("/foo1\rq")
+		if err := c.Top(&Options{}, context.Background(), screen); err != nil {
+			t.Fatal(err)
+		}
+
+		frames := strings.Split(b.String(), "\033[H\033[2J")
+		last := frames[len(frames)-2]
+		if !strings.Contains(last, "foo1") || strings.Contains(last, "foo0") {
+			t.Errorf("expected only foo1, got:\n%s", last)
+		}
+	})
+
+	t.Run("Describe", func(t *testing.T) {
+		b.Reset()
+		screen.In = strings.NewReader("3j\r")
+		if err := c.Top(&Options{}, context.Background(), screen); err != nil {
+			t.Fatal(err)
+		}
+
+		frames := strings.Split(b.String(), "\033[H\033[2J")
+		last := frames[len(frames)-2]
+		if !strings.Contains(last, "Name:  server1") {
+			t.Errorf("expected server1 described, got:\n%s", last)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		screen.In = strings.NewReader("2q")
+		if err := newTestBrokenClient(b).Top(&Options{}, context.Background(), screen); err != nil {
+			t.Fatal(err)
+		}
+
+		frames := strings.Split(b.String(), "\033[H\033[2J")
+		last := frames[len(frames)-2]
+		if !strings.Contains(last, "error: rpc error: code = NotFound") {
+			t.Errorf("expected the error in the status line, got:\n%s", last)
+		}
+	})
+
+	t.Run("FirstRefreshError", func(t *testing.T) {
+		screen.In = strings.NewReader("q")
+		c := &Client{w: &bytes.Buffer{}, cc: &fakeChannelzClient{topChannelsErr: status.Error(codes.Unavailable, "unavailable")}}
+		if err := c.Top(&Options{}, context.Background(), screen); status.Code(err) != codes.Unavailable {
+			t.Errorf("expected Unavailable, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package channelz
+
+import (
+	"context"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// ChannelNode is a channel with its nested channels, subchannels and sockets resolved.
+type ChannelNode struct {
+	Ref         *channelzpb.ChannelRef  `json:"ref"`
+	Data        *channelzpb.ChannelData `json:"data"`
+	Channels    []*ChannelNode          `json:"channels,omitempty"`
+	Subchannels []*SubchannelNode       `json:"subchannels,omitempty"`
+	Sockets     []*SocketView           `json:"sockets,omitempty"`
+}
+
+// SubchannelNode is a subchannel with its nested channels, subchannels and sockets resolved.
+type SubchannelNode struct {
+	Ref         *channelzpb.SubchannelRef `json:"ref"`
+	Data        *channelzpb.ChannelData   `json:"data"`
+	Channels    []*ChannelNode            `json:"channels,omitempty"`
+	Subchannels []*SubchannelNode         `json:"subchannels,omitempty"`
+	Sockets     []*SocketView             `json:"sockets,omitempty"`
+}
+
+// ServerNode is a server with its listen sockets and accepted sockets resolved.
+type ServerNode struct {
+	Ref           *channelzpb.ServerRef  `json:"ref"`
+	Data          *channelzpb.ServerData `json:"data"`
+	ListenSockets []*ListenSocketNode    `json:"listen_sockets,omitempty"`
+	// Sockets holds the accepted sockets which match none of the listen sockets.
+	Sockets []*SocketView `json:"sockets,omitempty"`
+}
+
+// ListenSocketNode is a listen socket with the sockets accepted on it.
+type ListenSocketNode struct {
+	Socket   *SocketView   `json:"socket"`
+	Accepted []*SocketView `json:"accepted,omitempty"`
+}
+
+func (cc *Client) TreeTopChannels(opts *Options, ctx context.Context) error {
+	var nodes []*ChannelNode
+	err := cc.visitTopChannels(ctx, func(channel *channelzpb.Channel) error {
+		node, err := cc.channelTree(ctx, channel)
+		if err != nil {
+			return err
+		}
+		if opts.document() {
+			nodes = append(nodes, node)
+			return nil
+		}
+
+		cc.printChannelTree(opts.palette(), node)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.document() {
+		return cc.encode(opts, nodes)
+	}
+	return nil
+}
+
+func (cc *Client) TreeServers(opts *Options, ctx context.Context) error {
+	var nodes []*ServerNode
+	err := cc.visitGetServers(ctx, func(server *channelzpb.Server) error {
+		node, err := cc.serverTree(ctx, server)
+		if err != nil {
+			return err
+		}
+		if opts.document() {
+			nodes = append(nodes, node)
+			return nil
+		}
+
+		cc.printServerTree(opts, node)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.document() {
+		return cc.encode(opts, nodes)
+	}
+	return nil
+}
+
+func (cc *Client) channelTree(ctx context.Context, channel *channelzpb.Channel) (*ChannelNode, error) {
+	channels, err := cc.channelTrees(ctx, channel.ChannelRef)
+	if err != nil {
+		return nil, err
+	}
+	subchannels, err := cc.subchannelTrees(ctx, channel.SubchannelRef)
+	if err != nil {
+		return nil, err
+	}
+	sockets, err := cc.getSockets(ctx, channel.SocketRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChannelNode{
+		Ref:         channel.Ref,
+		Data:        channel.Data,
+		Channels:    channels,
+		Subchannels: subchannels,
+		Sockets:     newSocketViews(sockets),
+	}, nil
+}
+
+func (cc *Client) channelTrees(ctx context.Context, refs []*channelzpb.ChannelRef) ([]*ChannelNode, error) {
+	var nodes []*ChannelNode
+	for _, ref := range refs {
+		res, err := cc.cc.GetChannel(ctx, &channelzpb.GetChannelRequest{ChannelId: ref.ChannelId})
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := cc.channelTree(ctx, res.Channel)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (cc *Client) subchannelTrees(ctx context.Context, refs []*channelzpb.SubchannelRef) ([]*SubchannelNode, error) {
+	var nodes []*SubchannelNode
+	for _, ref := range refs {
+		res, err := cc.cc.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: ref.SubchannelId})
+		if err != nil {
+			return nil, err
+		}
+
+		subch := res.Subchannel
+		channels, err := cc.channelTrees(ctx, subch.ChannelRef)
+		if err != nil {
+			return nil, err
+		}
+		subchannels, err := cc.subchannelTrees(ctx, subch.SubchannelRef)
+		if err != nil {
+			return nil, err
+		}
+		sockets, err := cc.getSockets(ctx, subch.SocketRef)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, &SubchannelNode{
+			Ref:         subch.Ref,
+			Data:        subch.Data,
+			Channels:    channels,
+			Subchannels: subchannels,
+			Sockets:     newSocketViews(sockets),
+		})
+	}
+	return nodes, nil
+}
+
+func (cc *Client) getSockets(ctx context.Context, refs []*channelzpb.SocketRef) ([]*channelzpb.Socket, error) {
+	var sockets []*channelzpb.Socket
+	for _, ref := range refs {
+		res, err := cc.cc.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: ref.SocketId})
+		if err != nil {
+			return nil, err
+		}
+
+		sockets = append(sockets, res.Socket)
+	}
+	return sockets, nil
+}
+
+func (cc *Client) serverTree(ctx context.Context, server *channelzpb.Server) (*ServerNode, error) {
+	node := &ServerNode{Ref: server.Ref, Data: server.Data}
+	listenSockets, err := cc.getSockets(ctx, server.ListenSocket)
+	if err != nil {
+		return nil, err
+	}
+	for _, socket := range listenSockets {
+		node.ListenSockets = append(node.ListenSockets, &ListenSocketNode{Socket: newSocketView(socket)})
+	}
+
+	// channelz does not link accepted sockets to their listen socket,
+	// so group them by the local port they were accepted on.
+	err = cc.visitGetServerSockets(ctx, server.Ref.ServerId, func(socket *channelzpb.Socket) error {
+		if lis := findListenSocket(node.ListenSockets, socket); lis != nil {
+			lis.Accepted = append(lis.Accepted, newSocketView(socket))
+		} else {
+			node.Sockets = append(node.Sockets, newSocketView(socket))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// findListenSocket returns the listen socket that accepted socket, matched by local address.
+func findListenSocket(listenSockets []*ListenSocketNode, socket *channelzpb.Socket) *ListenSocketNode {
+	for _, lis := range listenSockets {
+		if sameListenAddr(lis.Socket.GetLocal(), socket.GetLocal()) {
+			return lis
+		}
+	}
+	return nil
+}
+
+func (cc *Client) printChannelTree(p palette, node *ChannelNode) {
+	now := timeNow()
+
+	cc.printf("%s (ID:%d) [%s]\n",
+		node.Data.Target, node.Ref.ChannelId,
+		p.state(node.Data.State.State.String()))
+
+	elapesed := elapsedTimestamp(now, node.Data.LastCallStartedTimestamp)
+	cc.printf("  [Calls] Started:%v, Succeeded:%v, Failed:%v, Last:%v\n", node.Data.CallsStarted, node.Data.CallsSucceeded, p.failedCount(node.Data.CallsFailed), elapesed)
+
+	for _, socket := range node.Sockets {
+		cc.printSocket("  ", socket)
+	}
+
+	if len(node.Channels) != 0 {
+		cc.printf("  [Channels]\n")
+	}
+	for _, ch := range node.Channels {
+		cc.printf("    |-- %s (ID:%d) [%s]\n",
+			ch.Data.Target, ch.Ref.ChannelId,
+			p.state(ch.Data.State.State.String()))
+		cc.printTreeBody(now, p, "          ", ch.Data, ch.Channels, ch.Subchannels, ch.Sockets)
+	}
+
+	if len(node.Subchannels) != 0 {
+		cc.printf("  [Subchannels]\n")
+	}
+	for _, subch := range node.Subchannels {
+		cc.printf("    |-- %s (ID:%d) [%s]\n",
+			subch.Data.Target, subch.Ref.SubchannelId,
+			p.state(subch.Data.State.State.String()))
+		cc.printTreeBody(now, p, "          ", subch.Data, subch.Channels, subch.Subchannels, subch.Sockets)
+	}
+
+	cc.printf("\n")
+}
+
+// printTreeBody prints the calls, the sockets and, recursively, the nested channels and subchannels
+// of a channel or subchannel under indent.
+func (cc *Client) printTreeBody(now time.Time, p palette, indent string, data *channelzpb.ChannelData,
+	channels []*ChannelNode, subchannels []*SubchannelNode, sockets []*SocketView) {
+	elapesed := elapsedTimestamp(now, data.LastCallStartedTimestamp)
+	cc.printf("%s[Calls]: Started:%v, Succeeded:%v, Failed:%v, Last:%s\n", indent, data.CallsStarted, data.CallsSucceeded, p.failedCount(data.CallsFailed), elapesed)
+
+	for _, socket := range sockets {
+		cc.printSocket(indent, socket)
+	}
+	for _, ch := range channels {
+		cc.printf("%s[Channel] %s (ID:%d) [%s]\n",
+			indent, ch.Data.Target, ch.Ref.ChannelId, p.state(ch.Data.State.State.String()))
+		cc.printTreeBody(now, p, indent+"    ", ch.Data, ch.Channels, ch.Subchannels, ch.Sockets)
+	}
+	for _, subch := range subchannels {
+		cc.printf("%s[Subchannel] %s (ID:%d) [%s]\n",
+			indent, subch.Data.Target, subch.Ref.SubchannelId, p.state(subch.Data.State.State.String()))
+		cc.printTreeBody(now, p, indent+"    ", subch.Data, subch.Channels, subch.Subchannels, subch.Sockets)
+	}
+}
+
+func (cc *Client) printSocket(indent string, socket *SocketView) {
+	cc.printf("%s[Socket] ID:%v, Name:%v, RemoteName:%v", indent, socket.Ref.SocketId, socket.Ref.Name, socket.RemoteName)
+	cc.printf(", Local:%s Remote:%s\n", socket.LocalAddress, socket.RemoteAddress)
+}
+
+func (cc *Client) printServerTree(opts *Options, node *ServerNode) {
+	now := timeNow()
+	p := opts.palette()
+
+	cc.printf("ID: %v, Name: %v\n", node.Ref.ServerId, node.Ref.Name)
+
+	elapesed := elapsedTimestamp(now, node.Data.LastCallStartedTimestamp)
+	cc.printf("    [Calls]: Started:%v Succeeded:%v, Failed:%v, Last:%s\n", node.Data.CallsStarted, node.Data.CallsSucceeded, p.failedCount(node.Data.CallsFailed), elapesed)
+
+	for _, lis := range node.ListenSockets {
+		socket := lis.Socket
+		cc.printf("    [Socket] ID:%v, Name:%v, RemoteName:%v", socket.Ref.SocketId, socket.Ref.Name, socket.RemoteName)
+		if socket.LocalAddress != "" {
+			cc.printf(", Local:%s", socket.LocalAddress)
+		}
+		cc.printf("\n")
+		cc.printServerSockets(now, p, "        ", lis.Accepted, opts.MaxSockets)
+	}
+
+	if len(node.Sockets) != 0 {
+		cc.printf("    [Sockets]\n")
+		cc.printServerSockets(now, p, "        ", node.Sockets, opts.MaxSockets)
+	}
+
+	cc.printf("\n")
+}
+
+func (cc *Client) printServerSockets(now time.Time, p palette, indent string, sockets []*SocketView, max int) {
+	for i, socket := range sockets {
+		if max > 0 && i >= max {
+			cc.printf("%s... and %d more\n", indent, len(sockets)-max)
+			return
+		}
+
+		cc.printf("%s|-- [Socket] ID:%v, Remote:%s, Streams: Started:%v, Succeeded:%v, Failed:%v, LastActivity:%s\n",
+			indent, socket.Ref.SocketId, decorateEmpty(socket.RemoteAddress),
+			socket.Data.StreamsStarted, socket.Data.StreamsSucceeded, p.failedCount(socket.Data.StreamsFailed),
+			elapsedTimestamp(now, lastSocketActivity(socket.Data)))
+	}
+}
@@ -0,0 +1,173 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+func TestTreeServers(t *testing.T) {
+	b := &bytes.Buffer{}
+	ctx := context.Background()
+	c := newTestClient1(b)
+
+	t.Run("all", func(t *testing.T) {
+		expected := `
+ID: 0, Name: server0
+    [Calls]: Started:100 Succeeded:90, Failed:10, Last:none
+    [Socket] ID:0, Name:sock0, RemoteName:, Local:127.0.1.2:9000
+
+ID: 1, Name: server1
+    [Calls]: Started:110 Succeeded:99, Failed:11, Last:0ms
+    [Socket] ID:1, Name:sock1, RemoteName:, Local:127.0.1.2:9001
+        |-- [Socket] ID:7, Remote:10.0.0.1:40000, Streams: Started:10, Succeeded:9, Failed:1, LastActivity:0ms
+        |-- [Socket] ID:8, Remote:10.0.0.2:40001, Streams: Started:20, Succeeded:18, Failed:2, LastActivity:0ms
+        |-- [Socket] ID:9, Remote:10.0.0.3:40002, Streams: Started:30, Succeeded:27, Failed:3, LastActivity:0ms
+`
+		b.Reset()
+		_ = c.TreeServers(&Options{}, ctx)
+		assertOutput(t, expected, b.String())
+	})
+
+	t.Run("MaxSockets", func(t *testing.T) {
+		expected := `
+ID: 0, Name: server0
+    [Calls]: Started:100 Succeeded:90, Failed:10, Last:none
+    [Socket] ID:0, Name:sock0, RemoteName:, Local:127.0.1.2:9000
+
+ID: 1, Name: server1
+    [Calls]: Started:110 Succeeded:99, Failed:11, Last:0ms
+    [Socket] ID:1, Name:sock1, RemoteName:, Local:127.0.1.2:9001
+        |-- [Socket] ID:7, Remote:10.0.0.1:40000, Streams: Started:10, Succeeded:9, Failed:1, LastActivity:0ms
+        ... and 2 more
+`
+		b.Reset()
+		_ = c.TreeServers(&Options{MaxSockets: 1}, ctx)
+		assertOutput(t, expected, b.String())
+	})
+}
+
+func TestTreeTopChannelsStructured(t *testing.T) {
+	b := &bytes.Buffer{}
+	ctx := context.Background()
+	c := newTestClient1(b)
+
+	t.Run("json", func(t *testing.T) {
+		b.Reset()
+		if err := c.TreeTopChannels(&Options{Json: true}, ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		var nodes []struct {
+			Subchannels []struct {
+				Sockets []json.RawMessage `json:"sockets"`
+			} `json:"subchannels"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &nodes); err != nil {
+			t.Fatal(err)
+		}
+		if len(nodes) != 2 {
+			t.Fatalf("expected 2 top channels, got %d", len(nodes))
+		}
+		if n := len(nodes[1].Subchannels); n != 4 {
+			t.Fatalf("expected 4 subchannels, got %d", n)
+		}
+		if n := len(nodes[1].Subchannels[0].Sockets); n != 1 {
+			t.Fatalf("expected 1 socket, got %d", n)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		b.Reset()
+		if err := c.TreeTopChannels(&Options{Yaml: true}, ctx); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(b.String(), "- ref:\n    name: foo0\n") {
+			t.Errorf("unexpected yaml:\n%s", b.String())
+		}
+	})
+}
+
+// newTestNestedClient returns a client of a top channel 1 with a nested channel 2, which has
+// the subchannel 20, and a subchannel 10, which has the nested channel 3 and its subchannel 30.
+func newTestNestedClient(b *bytes.Buffer) *Client {
+	newChannel := func(id int64, target string, channels []int64, subchannels []int64) *channelzpb.Channel {
+		ch := &channelzpb.Channel{
+			Ref: &channelzpb.ChannelRef{ChannelId: id},
+			Data: &channelzpb.ChannelData{
+				State:        &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_READY},
+				Target:       target,
+				CallsStarted: id,
+			},
+		}
+		for _, ref := range channels {
+			ch.ChannelRef = append(ch.ChannelRef, &channelzpb.ChannelRef{ChannelId: ref})
+		}
+		for _, ref := range subchannels {
+			ch.SubchannelRef = append(ch.SubchannelRef, &channelzpb.SubchannelRef{SubchannelId: ref})
+		}
+		return ch
+	}
+	newSubchannel := func(id int64, target string, channels []int64) *channelzpb.Subchannel {
+		subch := &channelzpb.Subchannel{
+			Ref: &channelzpb.SubchannelRef{SubchannelId: id},
+			Data: &channelzpb.ChannelData{
+				State:        &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_TRANSIENT_FAILURE},
+				Target:       target,
+				CallsStarted: id,
+				CallsFailed:  1,
+			},
+		}
+		for _, ref := range channels {
+			subch.ChannelRef = append(subch.ChannelRef, &channelzpb.ChannelRef{ChannelId: ref})
+		}
+		return subch
+	}
+
+	top := newChannel(1, "lb.test.com", []int64{2}, []int64{10})
+	return &Client{
+		w: b,
+		cc: &fakeChannelzClient{
+			topChannels: []*channelzpb.Channel{top},
+			channels: []*channelzpb.Channel{
+				top,
+				newChannel(2, "nested.test.com", nil, []int64{20}),
+				newChannel(3, "child.test.com", nil, []int64{30}),
+			},
+			subchannels: []*channelzpb.Subchannel{
+				newSubchannel(10, "10.0.0.10:443", []int64{3}),
+				newSubchannel(20, "10.0.0.20:443", nil),
+				newSubchannel(30, "10.0.0.30:443", nil),
+			},
+		},
+	}
+}
+
+func TestTreeTopChannelsNested(t *testing.T) {
+	b := &bytes.Buffer{}
+	c := newTestNestedClient(b)
+	if err := c.TreeTopChannels(&Options{}, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, `
+lb.test.com (ID:1) [READY]
+  [Calls] Started:1, Succeeded:0, Failed:0, Last:none
+  [Channels]
+    |-- nested.test.com (ID:2) [READY]
+          [Calls]: Started:2, Succeeded:0, Failed:0, Last:none
+          [Subchannel] 10.0.0.20:443 (ID:20) [TRANSIENT_FAILURE]
+              [Calls]: Started:20, Succeeded:0, Failed:1, Last:none
+  [Subchannels]
+    |-- 10.0.0.10:443 (ID:10) [TRANSIENT_FAILURE]
+          [Calls]: Started:10, Succeeded:0, Failed:1, Last:none
+          [Channel] child.test.com (ID:3) [READY]
+              [Calls]: Started:3, Succeeded:0, Failed:0, Last:none
+              [Subchannel] 10.0.0.30:443 (ID:30) [TRANSIENT_FAILURE]
+                  [Calls]: Started:30, Succeeded:0, Failed:1, Last:none
+`, b.String())
+}
@@ -0,0 +1,115 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Marks of the rows in watch, relative to the previous sample.
+const (
+	markNew     = "+"
+	markChanged = "~"
+	markRemoved = "-"
+)
+
+// Watch renders the rows of kind every opts.Interval until ctx is done, with the per second
+// rates computed from consecutive samples. Rows are marked new, changed or removed, and
+// colored so with colors. A failed sample shows its error and is retried on the next tick.
+func (cc *Client) Watch(opts *Options, ctx context.Context, kind string) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	clearScreen := isTerminal(cc.w)
+	p := opts.palette()
+
+	var prev map[string]*Row
+	var prevAt time.Time
+	for ctx.Err() == nil {
+		cur := make(map[string]*Row)
+		var rows []*Row
+		err := cc.visitRows(ctx, kind, func(row *Row) error {
+			cur[row.Key()] = row
+			rows = append(rows, row)
+			return nil
+		})
+		if ctx.Err() != nil {
+			break
+		}
+		at := timeNow()
+
+		if clearScreen {
+			cc.printf("\033[H\033[2J")
+		}
+		cc.printf("Every %s: %s\t%s\n\n", interval, kind, at.Format(time.RFC3339))
+		if err != nil {
+			// keep the previous sample, to compute the rates of the next one.
+			cc.printf("error: %v\n", err)
+		} else {
+			cc.printWatchRows(p, rows, prev, cur, at.Sub(prevAt))
+			prev, prevAt = cur, at
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+		}
+	}
+	return nil
+}
+
+// markColors are the colors of the marked rows.
+var markColors = map[string]string{
+	markNew:     colorGreen,
+	markChanged: colorYellow,
+	markRemoved: colorRed,
+}
+
+func (cc *Client) printWatchRows(p palette, rows []*Row, prev, cur map[string]*Row, elapsed time.Duration) {
+	now := timeNow()
+	for _, row := range prev {
+		if cur[row.Key()] == nil {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+	cc.printf("%s\t%s\t%-40s\t%-17s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		" ", "ID", "Name", "State", "Calls", "Success", "Fail", "Calls/s", "Success/s", "Fail/s", "LastCall")
+	for _, row := range rows {
+		last := prev[row.Key()]
+		mark := " "
+		switch {
+		case prev == nil:
+		case cur[row.Key()] == nil:
+			mark = markRemoved
+		case last == nil:
+			mark = markNew
+		case rowChanged(last, row):
+			mark = markChanged
+		}
+
+		rate := rowRate(last, row, elapsed)
+		line := fmt.Sprintf("%s\t%d\t%-40s\t%-17s\t%-6d\t%-6d\t%-6d\t%-7.1f\t%-9.1f\t%-6.1f\t%s",
+			mark,
+			row.ID,
+			row.DisplayName(),
+			decorateEmpty(row.State),
+			row.Started,
+			row.Succeeded,
+			row.Failed,
+			rate.Calls,
+			rate.Succeeded,
+			rate.Failed,
+			elapsedTimestamp(now, row.LastCall),
+		)
+		cc.printf("%s\n", p.paint(markColors[mark], line))
+	}
+}
+
+func rowChanged(prev, cur *Row) bool {
+	return prev.State != cur.State || prev.Started != cur.Started ||
+		prev.Succeeded != cur.Succeeded || prev.Failed != cur.Failed
+}
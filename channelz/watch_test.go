@@ -0,0 +1,125 @@
+package channelz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newWatchChannel(id int64, started, failed int64) *channelzpb.Channel {
+	return &channelzpb.Channel{
+		Ref: &channelzpb.ChannelRef{ChannelId: id},
+		Data: &channelzpb.ChannelData{
+			State:          &channelzpb.ChannelConnectivityState{State: channelzpb.ChannelConnectivityState_READY},
+			Target:         "watch.test.com",
+			CallsStarted:   started,
+			CallsSucceeded: started - failed,
+			CallsFailed:    failed,
+		},
+	}
+}
+
+func TestWatch(t *testing.T) {
+	b := &bytes.Buffer{}
+	fake := &fakeChannelzClient{
+		topChannels: []*channelzpb.Channel{
+			newWatchChannel(1, 100, 10),
+			newWatchChannel(2, 50, 0),
+		},
+	}
+
+	now := fixedTime
+	defer func() { timeNow = func() time.Time { return fixedTime } }()
+	timeNow = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &Client{
+		w: b,
+		cc: &hookChannelzClient{
+			fakeChannelzClient: fake,
+			hook: func(n int) {
+				switch n {
+				case 2:
+					now = now.Add(2 * time.Second)
+					fake.topChannels = []*channelzpb.Channel{
+						newWatchChannel(1, 120, 14),
+						newWatchChannel(3, 0, 0),
+					}
+				case 3:
+					cancel()
+				}
+			},
+		},
+	}
+
+	expected := `
+Every 1ms: channel	2018-12-01T21:33:20Z
+
+ 	ID	Name                                    	State            	Calls	Success	Fail	Calls/s	Success/s	Fail/s	LastCall
+ 	1	watch.test.com                          	READY            	100   	90    	10    	0.0    	0.0      	0.0   	none
+ 	2	watch.test.com                          	READY            	50    	50    	0     	0.0    	0.0      	0.0   	none
+Every 1ms: channel	2018-12-01T21:33:22Z
+
+ 	ID	Name                                    	State            	Calls	Success	Fail	Calls/s	Success/s	Fail/s	LastCall
+~	1	watch.test.com                          	READY            	120   	106   	14    	10.0   	8.0      	2.0   	none
+-	2	watch.test.com                          	READY            	50    	50    	0     	0.0    	0.0      	0.0   	none
++	3	watch.test.com                          	READY            	0     	0     	0     	0.0    	0.0      	0.0   	none
+`
+	if err := c.Watch(&Options{Interval: time.Millisecond}, ctx, KindChannel); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, expected, b.String())
+}
+
+func TestWatchError(t *testing.T) {
+	b := &bytes.Buffer{}
+	fake := &fakeChannelzClient{
+		topChannels: []*channelzpb.Channel{newWatchChannel(1, 100, 10)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &Client{
+		w: b,
+		cc: &hookChannelzClient{
+			fakeChannelzClient: fake,
+			hook: func(n int) {
+				switch n {
+				case 2:
+					fake.topChannelsErr = status.Error(codes.Unavailable, "unavailable")
+				case 3:
+					fake.topChannelsErr = nil
+					fake.topChannels = []*channelzpb.Channel{newWatchChannel(1, 120, 14), newWatchChannel(3, 0, 0)}
+				case 4:
+					cancel()
+				}
+			},
+		},
+	}
+
+	if err := c.Watch(&Options{Interval: time.Millisecond, Color: ColorAlways}, ctx, KindChannel); err != nil {
+		t.Fatal(err)
+	}
+
+	// the failed sample is shown, and the next one is compared with the sample before it.
+	frames := strings.Split(b.String(), "Every ")
+	if len(frames) != 4 {
+		t.Fatalf("expected 3 frames, got:\n%s", b.String())
+	}
+	if !strings.Contains(frames[2], "error: rpc error: code = Unavailable") {
+		t.Errorf("expected the error in the second frame, got:\n%s", frames[2])
+	}
+	last := showColors.Replace(frames[3])
+	for _, prefix := range []string{"<yellow>~\t1\t", "<green>+\t3\t"} {
+		if !strings.Contains(last, prefix) {
+			t.Errorf("expected %q in the last frame, got:\n%s", prefix, last)
+		}
+	}
+}
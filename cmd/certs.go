@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type CertsCommand struct {
+	cmd            *cobra.Command
+	opts           *channelz.Options
+	expiringWithin string
+}
+
+func NewCertsCommand(opts *channelz.Options) *CertsCommand {
+	c := &CertsCommand{
+		cmd: &cobra.Command{
+			Use:          "certs",
+			Short:        "list the local and peer TLS certificates of every socket",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().StringVar(&c.expiringWithin, "expiring-within", "", "only certificates expiring within this duration, e.g. 30d or 12h")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *CertsCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *CertsCommand) Run(_ *cobra.Command, _ []string) error {
+	if c.expiringWithin != "" {
+		d, err := channelz.ParseDuration(c.expiringWithin)
+		if err != nil {
+			return fmt.Errorf("--expiring-within: %v", err)
+		}
+		c.opts.ExpiringWithin = d
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.ListCerts(c.opts, ctx)
+}
@@ -22,14 +22,16 @@ type DescribeCommand struct {
 func NewDescribeCommand(opts *channelz.Options) *DescribeCommand {
 	c := &DescribeCommand{
 		cmd: &cobra.Command{
-			Use:          "describe (channel|server|serversocket) (NAME|ID)",
-			Short:        "describe (channel|server|serversocket) (NAME|ID)",
+			Use:          "describe (channel|server|socket|serversocket) (NAME|PATTERN|ID)",
+			Short:        "describe (channel|server|socket|serversocket) (NAME|PATTERN|ID)",
 			Aliases:      []string{"desc", "d"},
 			Args:         cobra.ExactArgs(2),
 			SilenceUsage: true,
 		},
 		opts: opts,
 	}
+	c.cmd.Flags().BoolVar(&opts.Regex, "regex", false, "match NAME as a regular expression")
+	c.cmd.Flags().BoolVar(&opts.Unique, "unique", false, "fail listing the candidates when NAME matches more than one")
 	c.cmd.RunE = c.Run
 	return c
 }
@@ -59,7 +61,7 @@ func (c *DescribeCommand) Run(_ *cobra.Command, args []string) error {
 		return cc.DescribeChannel(c.opts, ctx, name)
 	case "server", "s":
 		return cc.DescribeServer(c.opts, ctx, name)
-	case "serversocket", "so", "ss":
+	case "socket", "sk", "serversocket", "so", "ss":
 		return cc.DescribeServerSocket(c.opts, ctx, name)
 	default:
 		_ = c.cmd.Usage()
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type DiffCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewDiffCommand(opts *channelz.Options) *DiffCommand {
+	c := &DiffCommand{
+		cmd: &cobra.Command{
+			Use:          "diff BEFORE [AFTER]",
+			Short:        "compare two snapshots, or a snapshot against the live target when AFTER is omitted",
+			Args:         cobra.RangeArgs(1, 2),
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *DiffCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *DiffCommand) Run(_ *cobra.Command, args []string) error {
+	before, err := readSnapshotFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 2 {
+		after, err := readSnapshotFile(args[1])
+		if err != nil {
+			return err
+		}
+		return channelz.NewClient(nil, c.opts.Output).Diff(c.opts, before, after)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	after, err := cc.TakeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	return cc.Diff(c.opts, before, after)
+}
+
+func readSnapshotFile(name string) (*channelz.Snapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer iox.Close(f)
+
+	s, err := channelz.ReadSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", name, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type EventsCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewEventsCommand(opts *channelz.Options) *EventsCommand {
+	c := &EventsCommand{
+		cmd: &cobra.Command{
+			Use:          "events [channel (NAME|PATTERN|ID)]",
+			Short:        "show the trace events of channels and their subchannels in one timeline",
+			Aliases:      []string{"ev"},
+			Args:         cobra.MaximumNArgs(1),
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().BoolVar(&opts.Regex, "regex", false, "match NAME as a regular expression")
+	c.cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "poll and print new events as they appear")
+	c.cmd.Flags().DurationVar(&opts.Interval, "interval", 2*time.Second, "poll interval with --follow")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *EventsCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *EventsCommand) Run(_ *cobra.Command, args []string) error {
+	// --follow polls until it is interrupted, instead of for 30s.
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if c.opts.Follow {
+		ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	} else {
+		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	}
+	defer cancel()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.ListEvents(c.opts, ctx, name)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type FlowCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewFlowCommand(opts *channelz.Options) *FlowCommand {
+	c := &FlowCommand{
+		cmd: &cobra.Command{
+			Use:          "flow",
+			Short:        "list the sockets showing symptoms of HTTP/2 flow-control stalls",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().Int64Var(&opts.WindowBelow, "window-below", channelz.DefaultWindowBelow, "report flow-control windows smaller than this many bytes")
+	c.cmd.Flags().DurationVar(&opts.Idle, "idle", channelz.DefaultIdle, "report active streams without a message for this long")
+	c.cmd.Flags().BoolVar(&opts.All, "all", false, "list every socket, including the healthy ones")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *FlowCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *FlowCommand) Run(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.FlowSockets(c.opts, ctx)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type GraphCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewGraphCommand(opts *channelz.Options) *GraphCommand {
+	c := &GraphCommand{
+		cmd: &cobra.Command{
+			Use:          "graph",
+			Short:        "render the topology of the channels and servers as a Graphviz graph (-o dot) or a Mermaid flowchart (-o mermaid)",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().BoolVar(&opts.Collapse, "collapse", false, "draw the subchannels of a channel to the same target as one node")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *GraphCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *GraphCommand) Run(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.Graph(c.opts, ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/bingoohuang/channelzcli/channelz"
@@ -22,14 +23,24 @@ type ListCommand struct {
 func NewListCommand(opts *channelz.Options) *ListCommand {
 	c := &ListCommand{
 		cmd: &cobra.Command{
-			Use:          "list (channel|server|serversocket)",
-			Short:        "list (channel|server|serversocket)",
+			Use:          "list (channel|subchannel|server|serversocket|socket)",
+			Short:        "list (channel|subchannel|server|serversocket|socket)",
 			Args:         cobra.ExactArgs(1),
 			Aliases:      []string{"ls"},
 			SilenceUsage: true,
 		},
 		opts: opts,
 	}
+	c.cmd.Flags().DurationVar(&opts.Rate, "rate", 0, "sample twice this far apart and add per second rate columns, e.g. 10s")
+	c.cmd.Flags().StringVar(&opts.SortBy, "sort-by", "", "sort ascending by "+strings.Join(channelz.RowSortKeys(), ", "))
+	c.cmd.Flags().BoolVar(&opts.Reverse, "reverse", false, "reverse the --sort-by order")
+	c.cmd.Flags().StringVar(&opts.Filter.State, "state", "", "only these comma separated states, e.g. TRANSIENT_FAILURE,CONNECTING")
+	c.cmd.Flags().StringVar(&opts.Filter.Target, "target", "", "only targets matching this regular expression")
+	c.cmd.Flags().StringVar(&opts.Filter.Name, "name", "", "only names matching this regular expression")
+	c.cmd.Flags().Int64Var(&opts.Filter.MinFailed, "min-failed", 0, "only with at least N failed calls or streams")
+	c.cmd.Flags().StringVar(&opts.Filter.FailureRatio, "failure-ratio", "", "only with a failed share of the finished calls matching, e.g. '>0.05' or '>=5%'")
+	c.cmd.Flags().DurationVar(&opts.Filter.IdleFor, "idle-for", 0, "only without a call or socket activity for this long, e.g. 10m")
+	c.cmd.Flags().StringVar(&opts.Filter.Remote, "remote", "", "only with a remote address in this CIDR, e.g. 10.0.0.0/8")
 	c.cmd.RunE = c.Run
 	return c
 }
@@ -39,7 +50,7 @@ func (c *ListCommand) Command() *cobra.Command {
 }
 
 func (c *ListCommand) Run(_ *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second+c.opts.Rate)
 	defer cancel()
 	typ := args[0]
 
@@ -54,16 +65,15 @@ func (c *ListCommand) Run(_ *cobra.Command, args []string) error {
 	cc := channelz.NewClient(conn, c.opts.Output)
 
 	switch typ {
-	case "channel", "c":
-		return cc.ListTopChannels(c.opts, ctx)
-	case "server", "s":
-		return cc.ListServers(c.opts, ctx)
 	case "serversocket", "so", "ss":
-		cc.ListServerSockets(ctx)
-	default:
+		return cc.List(c.opts, ctx, channelz.KindServerSocket)
+	}
+
+	kind, err := channelz.ParseKind(typ)
+	if err != nil {
 		_ = c.cmd.Usage()
 		os.Exit(1)
 	}
 
-	return nil
+	return cc.List(c.opts, ctx, kind)
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type ReportCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+	// file is the HTML file to write, which shadows the persistent -o output format.
+	file string
+}
+
+func NewReportCommand(opts *channelz.Options) *ReportCommand {
+	c := &ReportCommand{
+		cmd: &cobra.Command{
+			Use:   "report",
+			Short: "write a self-contained HTML report of every channelz entity",
+			Long: "write a self-contained HTML report of every channelz entity: sortable tables, trace events,\n" +
+				"certificates and a topology diagram. For report, -o (or -f) is the HTML file to write, not an output format.",
+			Example:      "  channelzcli -k --addr localhost:8000 report -o report.html",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().StringVarP(&c.file, "output", "o", "", "HTML file to write the report to, the standard output when empty")
+	c.cmd.Flags().StringVarP(&c.file, "file", "f", "", "alias of -o")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *ReportCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *ReportCommand) Run(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	if c.file == "" {
+		return channelz.NewClient(conn, c.opts.Output).Report(c.opts, ctx)
+	}
+
+	f, err := os.Create(c.file)
+	if err != nil {
+		return err
+	}
+	if err := channelz.NewClient(conn, f).Report(c.opts, ctx); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/bingoohuang/channelzcli/channelz"
 	"github.com/bingoohuang/gg/pkg/v"
@@ -25,17 +26,48 @@ func NewRootCommand(r io.Reader, w io.Writer) *RootCommand {
 			},
 		},
 		opts: &channelz.Options{
-			Input:  r,
-			Output: w,
+			Input:     r,
+			Output:    w,
+			ErrOutput: os.Stderr,
 		},
 	}
 	c.cmd.PersistentFlags().BoolVarP(&c.opts.Json, "json", "j", false, "JSON output")
+	c.cmd.PersistentFlags().BoolVarP(&c.opts.Yaml, "yaml", "y", false, "YAML output")
+	c.cmd.PersistentFlags().StringVarP(&c.opts.Format, "output", "o", "", "output format: wide, csv, tsv, markdown, json, ndjson, yaml, dot, mermaid, jsonpath=..., go-template=..., go-template-file=... or custom-columns=...")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Query, "query", "", "jq-like expression evaluated over the JSON view of the result")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Color, "color", channelz.ColorAuto, "colorize the output: auto, always or never")
 	c.cmd.PersistentFlags().BoolVarP(&c.opts.Verbose, "verbose", "v", false, "verbose output")
 	c.cmd.PersistentFlags().BoolVarP(&c.opts.Insecure, "insecure", "k", true, "with insecure")
 	c.cmd.PersistentFlags().StringVarP(&c.opts.Address, "addr", "a", "", "address to gRPC server")
+	c.cmd.PersistentPreRunE = func(*cobra.Command, []string) error {
+		color, err := channelz.ParseColor(c.opts.Color)
+		if err != nil {
+			return err
+		}
+		c.opts.Color = color
+
+		// --json and --yaml are kept as aliases of -o json and -o yaml.
+		switch c.opts.Format {
+		case channelz.FormatJSON:
+			c.opts.Json = true
+		case channelz.FormatYAML:
+			c.opts.Yaml = true
+		}
+		return nil
+	}
 	c.cmd.AddCommand(NewListCommand(c.opts).Command())
 	c.cmd.AddCommand(NewTreeCommand(c.opts).Command())
 	c.cmd.AddCommand(NewDescribeCommand(c.opts).Command())
+	c.cmd.AddCommand(NewEventsCommand(c.opts).Command())
+	c.cmd.AddCommand(NewWatchCommand(c.opts).Command())
+	c.cmd.AddCommand(NewTopCommand(c.opts).Command())
+	c.cmd.AddCommand(NewSnapshotCommand(c.opts).Command())
+	c.cmd.AddCommand(NewDiffCommand(c.opts).Command())
+	c.cmd.AddCommand(NewCertsCommand(c.opts).Command())
+	c.cmd.AddCommand(NewTCPCommand(c.opts).Command())
+	c.cmd.AddCommand(NewFlowCommand(c.opts).Command())
+	c.cmd.AddCommand(NewGraphCommand(c.opts).Command())
+	c.cmd.AddCommand(NewReportCommand(c.opts).Command())
 	c.cmd.AddCommand(NewVersionCommand(c.opts).Command())
 	return c
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type SnapshotCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewSnapshotCommand(opts *channelz.Options) *SnapshotCommand {
+	c := &SnapshotCommand{
+		cmd: &cobra.Command{
+			Use:          "snapshot",
+			Short:        "write every channelz entity as JSON, to be compared later with diff",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *SnapshotCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *SnapshotCommand) Run(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.Snapshot(c.opts, ctx)
+}
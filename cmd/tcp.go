@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type TCPCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewTCPCommand(opts *channelz.Options) *TCPCommand {
+	c := &TCPCommand{
+		cmd: &cobra.Command{
+			Use:          "tcp",
+			Short:        "rank the client and server sockets by their TCP health",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	// The default is left to TCPSockets, as list binds --sort-by to the same option.
+	c.cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"rank by "+strings.Join(channelz.TCPSortKeys(), ", ")+" (default retransmits)")
+	c.cmd.Flags().IntVar(&opts.Top, "top", 0, "print only the N worst sockets")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *TCPCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *TCPCommand) Run(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.TCPSockets(c.opts, ctx)
+}
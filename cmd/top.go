@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+type TopCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewTopCommand(opts *channelz.Options) *TopCommand {
+	c := &TopCommand{
+		cmd: &cobra.Command{
+			Use:          "top",
+			Short:        "full screen view of channels, subchannels, servers and sockets sorted by live rates",
+			Args:         cobra.NoArgs,
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().DurationVar(&opts.Interval, "interval", 2*time.Second, "refresh interval")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *TopCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *TopCommand) Run(_ *cobra.Command, _ []string) error {
+	in := int(os.Stdin.Fd())
+	if !term.IsTerminal(in) {
+		return fmt.Errorf("top requires a terminal")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = term.Restore(in, state) }()
+
+	out := int(os.Stdout.Fd())
+	screen := &channelz.Screen{
+		In: os.Stdin,
+		Size: func() (int, int) {
+			width, height, err := term.GetSize(out)
+			if err != nil {
+				return 80, 24
+			}
+			return width, height
+		},
+	}
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.Top(c.opts, ctx, screen)
+}
@@ -27,6 +27,7 @@ func NewTreeCommand(opts *channelz.Options) *TreeCommand {
 		},
 		opts: opts,
 	}
+	c.cmd.Flags().IntVar(&opts.MaxSockets, "max-sockets", 0, "max server sockets to show per listen socket, 0 for all")
 	c.cmd.RunE = c.Run
 	return c
 }
@@ -49,9 +50,9 @@ func (c *TreeCommand) Run(_ *cobra.Command, args []string) error {
 
 	switch typ {
 	case "channel", "c":
-		cc.TreeTopChannels(ctx)
+		return cc.TreeTopChannels(c.opts, ctx)
 	case "server", "s":
-		cc.TreeServers(ctx)
+		return cc.TreeServers(c.opts, ctx)
 	default:
 		_ = c.cmd.Usage()
 		os.Exit(1)
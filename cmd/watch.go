@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bingoohuang/channelzcli/channelz"
+	"github.com/bingoohuang/gg/pkg/iox"
+	"github.com/spf13/cobra"
+)
+
+type WatchCommand struct {
+	cmd  *cobra.Command
+	opts *channelz.Options
+}
+
+func NewWatchCommand(opts *channelz.Options) *WatchCommand {
+	c := &WatchCommand{
+		cmd: &cobra.Command{
+			Use:          "watch (channel|subchannel|server|socket)",
+			Short:        "watch (channel|subchannel|server|socket) with per second rates",
+			Aliases:      []string{"w"},
+			Args:         cobra.ExactArgs(1),
+			SilenceUsage: true,
+		},
+		opts: opts,
+	}
+	c.cmd.Flags().DurationVar(&opts.Interval, "interval", 2*time.Second, "refresh interval")
+	c.cmd.RunE = c.Run
+	return c
+}
+
+func (c *WatchCommand) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *WatchCommand) Run(_ *cobra.Command, args []string) error {
+	kind, err := channelz.ParseKind(args[0])
+	if err != nil {
+		_ = c.cmd.Usage()
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := newGRPCConnection(dialCtx, c.opts.Address, c.opts.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to connect %v: %v", c.opts.Address, err)
+	}
+	defer iox.Close(conn)
+
+	cc := channelz.NewClient(conn, c.opts.Output)
+	return cc.Watch(c.opts, ctx, kind)
+}
@@ -6,8 +6,10 @@ require (
 	github.com/bingoohuang/gg v0.0.0-20220401032752-6d4a79888f0e
 	github.com/golang/protobuf v1.5.2
 	github.com/spf13/cobra v1.4.0
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 	google.golang.org/grpc v1.45.0
 	google.golang.org/protobuf v1.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (